@@ -0,0 +1,194 @@
+package feistel
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ErrValueNotAllowed is returned when a value isn't in any of the allowed intervals of an AllowedNetwork
+var ErrValueNotAllowed = errors.New("feistel: value is not allowed")
+
+// ErrNoAllowedValues is returned when an AllowedNetwork would have nothing to permute
+var ErrNoAllowedValues = errors.New("feistel: there are no allowed values")
+
+// Interval is an inclusive range of values from Min to Max
+type Interval struct {
+	Min, Max uint64
+}
+
+// AllowedNetwork permutes only the values in a set of intervals, so reserved or deleted ranges are never produced
+// and there are exactly as many outputs as allowed values. A value is ranked by its position among the allowed
+// values, the rank is mapped by a Network over the number of allowed values and unranked again, both with a
+// binary search over the intervals.
+type AllowedNetwork struct {
+	network   *Network
+	intervals []Interval
+	// offsets[i] is the rank of intervals[i].Min
+	offsets []uint64
+}
+
+var _ Mapper = (*AllowedNetwork)(nil)
+
+// NewAllowedNetwork creates an AllowedNetwork over the values in allowed, intervals can overlap and come in any order.
+// seed, rounds and opts are passed to NewNetwork, there's nothing past the last allowed value so epochs have no effect.
+func NewAllowedNetwork(allowed []Interval, seed uint64, rounds uint8, opts ...Option) (*AllowedNetwork, error) {
+	intervals, err := mergeIntervals(allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(intervals) == 0 {
+		return nil, ErrNoAllowedValues
+	}
+
+	offsets := make([]uint64, len(intervals))
+	var maxRank uint64
+
+	for i, interval := range intervals {
+		if i > 0 {
+			offsets[i] = maxRank + 1
+		}
+		// This can only reach 2^64 values when the single interval is the whole uint64 range
+		maxRank = offsets[i] + (interval.Max - interval.Min)
+	}
+
+	network, err := NewNetwork(maxRank, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AllowedNetwork{
+		network:   network,
+		intervals: intervals,
+		offsets:   offsets,
+	}, nil
+}
+
+// NewExcludedNetwork creates an AllowedNetwork over the values from 0 to maxValue that aren't in excluded
+func NewExcludedNetwork(maxValue uint64, excluded []Interval, seed uint64, rounds uint8, opts ...Option) (*AllowedNetwork, error) {
+	merged, err := mergeIntervals(excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed []Interval
+	next := uint64(0)
+	done := false
+
+	for _, interval := range merged {
+		if interval.Min > maxValue {
+			break
+		}
+
+		if interval.Min > next {
+			allowed = append(allowed, Interval{next, interval.Min - 1})
+		}
+
+		if interval.Max >= maxValue {
+			done = true
+			break
+		}
+		next = interval.Max + 1
+	}
+
+	if !done {
+		allowed = append(allowed, Interval{next, maxValue})
+	}
+
+	return NewAllowedNetwork(allowed, seed, rounds, opts...)
+}
+
+// mergeIntervals sorts intervals and joins the ones that overlap or touch
+func mergeIntervals(intervals []Interval) ([]Interval, error) {
+	sorted := slices.Clone(intervals)
+	slices.SortFunc(sorted, func(a, b Interval) int {
+		return cmp.Compare(a.Min, b.Min)
+	})
+
+	merged := sorted[:0]
+	for _, interval := range sorted {
+		if interval.Min > interval.Max {
+			return nil, fmt.Errorf("%w, minValue: %d, maxValue: %d", ErrMinGreaterThanMax, interval.Min, interval.Max)
+		}
+
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if interval.Min <= last.Max || interval.Min-1 == last.Max {
+				last.Max = max(last.Max, interval.Max)
+				continue
+			}
+		}
+
+		merged = append(merged, interval)
+	}
+
+	return merged, nil
+}
+
+// Network returns the underlying Network over the ranks of the allowed values
+func (a *AllowedNetwork) Network() *Network {
+	return a.network
+}
+
+// Intervals returns the allowed values as sorted intervals that don't overlap or touch
+func (a *AllowedNetwork) Intervals() []Interval {
+	return slices.Clone(a.intervals)
+}
+
+// MaxRank returns the number of allowed values minus one
+func (a *AllowedNetwork) MaxRank() uint64 {
+	return a.network.maxValue
+}
+
+// Map takes an allowed value and maps it to another allowed value
+func (a *AllowedNetwork) Map(value uint64) (uint64, error) {
+	return a.encode(value, a.network.Map)
+}
+
+// InvertMap performs an inversion of Map
+func (a *AllowedNetwork) InvertMap(value uint64) (uint64, error) {
+	return a.encode(value, a.network.InvertMap)
+}
+
+// Rank returns the position of value among the allowed values
+func (a *AllowedNetwork) Rank(value uint64) (uint64, error) {
+	i := sort.Search(len(a.intervals), func(i int) bool {
+		return a.intervals[i].Max >= value
+	})
+
+	if i == len(a.intervals) || value < a.intervals[i].Min {
+		return 0, fmt.Errorf("%w, value: %d", ErrValueNotAllowed, value)
+	}
+
+	return a.offsets[i] + (value - a.intervals[i].Min), nil
+}
+
+// Unrank performs an inversion of Rank
+func (a *AllowedNetwork) Unrank(rank uint64) (uint64, error) {
+	if rank > a.network.maxValue {
+		return 0, fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, rank, a.network.maxValue)
+	}
+
+	i := sort.Search(len(a.offsets), func(i int) bool {
+		return a.offsets[i] > rank
+	}) - 1
+
+	return a.intervals[i].Min + (rank - a.offsets[i]), nil
+}
+
+func (a *AllowedNetwork) encode(value uint64, fn func(uint64) (uint64, error)) (uint64, error) {
+	rank, err := a.Rank(value)
+	if err != nil {
+		return 0, err
+	}
+
+	mapped, err := fn(rank)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.Unrank(mapped)
+}
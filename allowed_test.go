@@ -0,0 +1,172 @@
+package feistel
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAllowedNetworkIsPermutationOfAllowed(t *testing.T) {
+	allowed := []Interval{{100, 199}, {5, 9}, {150, 250}, {251, 260}, {1000, 1000}, {2000, 2049}}
+	net, err := NewAllowedNetwork(allowed, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Interval{{5, 9}, {100, 260}, {1000, 1000}, {2000, 2049}}
+	intervals := net.Intervals()
+	if len(intervals) != len(expected) {
+		t.Fatalf("Expected intervals %v, got %v", expected, intervals)
+	}
+	for i := range expected {
+		if intervals[i] != expected[i] {
+			t.Fatalf("Expected intervals %v, got %v", expected, intervals)
+		}
+	}
+
+	if net.MaxRank() != 5+161+1+50-1 {
+		t.Fatalf("Expected %d allowed values, got %d", 5+161+1+50, net.MaxRank()+1)
+	}
+
+	isAllowed := func(value uint64) bool {
+		for _, interval := range expected {
+			if value >= interval.Min && value <= interval.Max {
+				return true
+			}
+		}
+		return false
+	}
+
+	seen := make(map[uint64]struct{})
+	for value := range uint64(2100) {
+		mapped, err := net.Map(value)
+		if !isAllowed(value) {
+			if !errors.Is(err, ErrValueNotAllowed) {
+				t.Fatalf("Expected ErrValueNotAllowed for %d, got %v", value, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !isAllowed(mapped) {
+			t.Fatalf("Mapped %d to %d which isn't allowed", value, mapped)
+		}
+
+		if _, ok := seen[mapped]; ok {
+			t.Fatalf("%d was mapped to twice", mapped)
+		}
+		seen[mapped] = struct{}{}
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != value {
+			t.Fatalf("Mapped %d to %d and inversion produced %d", value, mapped, inverted)
+		}
+	}
+
+	if uint64(len(seen)) != net.MaxRank()+1 {
+		t.Errorf("Expected %d outputs, got %d", net.MaxRank()+1, len(seen))
+	}
+}
+
+func TestAllowedNetworkRank(t *testing.T) {
+	net, err := NewAllowedNetwork([]Interval{{10, 19}, {30, 39}}, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rank := range uint64(20) {
+		value, err := net.Unrank(rank)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := 10 + rank
+		if rank >= 10 {
+			expected = 20 + rank
+		}
+
+		if value != expected {
+			t.Errorf("Expected rank %d to be %d, got %d", rank, expected, value)
+		}
+
+		if back, err := net.Rank(value); err != nil || back != rank {
+			t.Errorf("Expected %d to have rank %d, got %d, %v", value, rank, back, err)
+		}
+	}
+
+	if _, err := net.Unrank(20); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
+
+func TestExcludedNetwork(t *testing.T) {
+	net, err := NewExcludedNetwork(99, []Interval{{0, 4}, {50, 59}, {20, 24}, {95, 200}}, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Interval{{5, 19}, {25, 49}, {60, 94}}
+	intervals := net.Intervals()
+	if len(intervals) != len(expected) {
+		t.Fatalf("Expected intervals %v, got %v", expected, intervals)
+	}
+	for i := range expected {
+		if intervals[i] != expected[i] {
+			t.Fatalf("Expected intervals %v, got %v", expected, intervals)
+		}
+	}
+
+	if _, err := NewExcludedNetwork(99, []Interval{{0, 99}}, 42, 8); !errors.Is(err, ErrNoAllowedValues) {
+		t.Errorf("Expected ErrNoAllowedValues, got %v", err)
+	}
+
+	if _, err := NewExcludedNetwork(99, []Interval{{10, 5}}, 42, 8); !errors.Is(err, ErrMinGreaterThanMax) {
+		t.Errorf("Expected ErrMinGreaterThanMax, got %v", err)
+	}
+}
+
+func TestAllowedNetworkFullRange(t *testing.T) {
+	net, err := NewExcludedNetwork(math.MaxUint64, []Interval{{1 << 32, 1<<33 - 1}}, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if net.MaxRank() != math.MaxUint64-1<<32 {
+		t.Fatalf("Expected max rank %d, got %d", uint64(math.MaxUint64-1<<32), net.MaxRank())
+	}
+
+	for _, value := range []uint64{0, 1<<32 - 1, 1 << 33, math.MaxUint64} {
+		mapped, err := net.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped >= 1<<32 && mapped < 1<<33 {
+			t.Fatalf("Mapped %d to the excluded value %d", value, mapped)
+		}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != value {
+			t.Fatalf("Mapped %d to %d and inversion produced %d, %v", value, mapped, inverted, err)
+		}
+	}
+
+	whole, err := NewAllowedNetwork([]Interval{{0, math.MaxUint64}}, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if whole.MaxRank() != math.MaxUint64 {
+		t.Errorf("Expected the whole range to be allowed, got max rank %d", whole.MaxRank())
+	}
+
+	if _, err := NewAllowedNetwork(nil, 42, 8); !errors.Is(err, ErrNoAllowedValues) {
+		t.Errorf("Expected ErrNoAllowedValues, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDestinationTooShort is returned when the destination slice can't hold all of the results
+var ErrDestinationTooShort = errors.New("feistel: destination slice is shorter than the source")
+
+// MapSlice maps every index in src and writes the result to the same position in dst.
+// It's equivalent to calling Map for each index but the round keys are only derived once per epoch and the first
+// round is reused between consecutive indices that share it. That only saves part of one round per index, so expect
+// something like 10% over a loop calling Map at 8 rounds when src is mostly increasing, more with fewer rounds or
+// IndependentEpochKeys. dst must be at least as long as src, it can be src itself.
+// If an index can't be mapped an error is returned and dst is left partially written.
+func (n *Network) MapSlice(dst, src []uint64) error {
+	return n.encodeSlice(dst, src, false)
+}
+
+// InvertMapSlice performs an inversion of MapSlice
+func (n *Network) InvertMapSlice(dst, src []uint64) error {
+	return n.encodeSlice(dst, src, true)
+}
+
+// MapRange fills dst with the mapped values of the consecutive indices starting at start,
+// so dst[i] is the result of Map(start + i). With WithEpochs the range can cross max value.
+func (n *Network) MapRange(dst []uint64, start uint64) error {
+	return n.encodeRange(dst, start, false)
+}
+
+// InvertMapRange performs an inversion of MapRange
+func (n *Network) InvertMapRange(dst []uint64, start uint64) error {
+	return n.encodeRange(dst, start, true)
+}
+
+func (n *Network) encodeSlice(dst, src []uint64, invert bool) error {
+	if len(dst) < len(src) {
+		return fmt.Errorf("%w, dst: %d, src: %d", ErrDestinationTooShort, len(dst), len(src))
+	}
+
+	encoder := batchEncoder{network: n, invert: invert}
+
+	for i, index := range src {
+		value, err := encoder.encode(index)
+		if err != nil {
+			return err
+		}
+
+		dst[i] = value
+	}
+
+	return nil
+}
+
+func (n *Network) encodeRange(dst []uint64, start uint64, invert bool) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	end := start + uint64(len(dst)-1)
+
+	if end < start {
+		return fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, start, n.maxValue)
+	}
+
+	if !n.epochs && end > n.maxValue {
+		return fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, end, n.maxValue)
+	}
+
+	encoder := batchEncoder{network: n, invert: invert}
+
+	for i := range dst {
+		// The whole range has already been checked so this can only fail on the walk limit
+		value, err := encoder.encode(start + uint64(i))
+		if err != nil {
+			return err
+		}
+
+		dst[i] = value
+	}
+
+	return nil
+}
+
+// batchEncoder holds the state that can be shared between calls to encode for nearby indices
+type batchEncoder struct {
+	network    *Network
+	invert     bool
+	epoch      uint64
+	epochStart uint64
+	epochHash  uint64
+	first      roundCache
+}
+
+func (e *batchEncoder) encode(index uint64) (uint64, error) {
+	n := e.network
+	var epoch uint64
+	var epochStart uint64
+
+	// maxValue + 1 wraps to 0 for a full uint64 domain, which never gets here
+	if index > n.maxValue {
+		if !n.epochs {
+			return 0, fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, index, n.maxValue)
+		}
+
+		epoch = index / (n.maxValue + 1)
+		epochStart = index - (index % (n.maxValue + 1))
+	}
+
+	if epochStart != e.epochStart || e.first.keys == nil {
+		e.epoch = epoch
+		e.epochStart = epochStart
+		e.epochHash = n.epochHash(e.epoch)
+		e.first.setKeys(n, e.epochHash)
+	}
+
+	if n.maxValue == 0 {
+		return epochStart, nil
+	}
+
+	value, err := n.permute(e.epoch, e.epochHash, 0, index-epochStart, e.invert, &e.first)
+	if err != nil {
+		return 0, withIndex(err, index)
+	}
+
+	return value + epochStart, nil
+}
+
+// roundCache holds the work shared by the indices of one epoch, the round keys and
+// the last value computed for a round with the same key and input
+type roundCache struct {
+	keys  []uint64
+	key   uint64
+	input uint64
+	value uint64
+	set   bool
+}
+
+// setKeys computes the key of every round once for keyOffset so it isn't derived again for each index
+func (c *roundCache) setKeys(n *Network, keyOffset uint64) {
+	if c.keys == nil {
+		c.keys = make([]uint64, n.rounds)
+	}
+
+	for round := range c.keys {
+		c.keys[round] = n.roundKey(round, keyOffset)
+	}
+}
+
+func (c *roundCache) lookup(roundFunc RoundFunc, key, input, radix uint64) uint64 {
+	if !c.set || c.key != key || c.input != input {
+		c.key = key
+		c.input = input
+		c.value = roundFunc.Round(key, input, radix)
+		c.set = true
+	}
+
+	return c.value
+}
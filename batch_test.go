@@ -0,0 +1,257 @@
+package feistel
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMapSliceMatchesMap(t *testing.T) {
+	for _, settings := range buildTestSettings() {
+		t.Run(settings.String(), func(t *testing.T) {
+			net, err := createNetwork(settings, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			count := 3 * (settings.maxValue + 1)
+			if !settings.epochs {
+				count = settings.maxValue + 1
+			}
+
+			src := make([]uint64, count)
+			for i := range src {
+				// Walk backwards through the domain as well to make sure the cache isn't relying on order
+				if i%2 == 0 {
+					src[i] = uint64(i)
+				} else {
+					src[i] = count - uint64(i)
+				}
+			}
+
+			mapped := make([]uint64, len(src))
+			if err := net.MapSlice(mapped, src); err != nil {
+				t.Fatal(err)
+			}
+
+			inverted := make([]uint64, len(src))
+			if err := net.InvertMapSlice(inverted, mapped); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, index := range src {
+				expected, err := net.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mapped[i] != expected {
+					t.Fatalf("MapSlice mapped %d to %d but Map returned %d", index, mapped[i], expected)
+				}
+
+				if inverted[i] != index {
+					t.Fatalf("InvertMapSlice inverted %d to %d but expected %d", mapped[i], inverted[i], index)
+				}
+			}
+		})
+	}
+}
+
+func TestMapRangeMatchesMap(t *testing.T) {
+	for _, schedule := range keySchedulesToTest {
+		t.Run(schedule.String(), func(t *testing.T) {
+			net, err := NewNetwork(1000, 9, 8, WithEpochs(), WithEpochKeySchedule(schedule))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := uint64(500)
+			dst := make([]uint64, 2500)
+
+			if err := net.MapRange(dst, start); err != nil {
+				t.Fatal(err)
+			}
+
+			inverted := make([]uint64, len(dst))
+			if err := net.InvertMapSlice(inverted, dst); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, value := range dst {
+				index := start + uint64(i)
+				expected, err := net.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expected {
+					t.Fatalf("MapRange mapped %d to %d but Map returned %d", index, value, expected)
+				}
+
+				if inverted[i] != index {
+					t.Fatalf("Inverted %d to %d but expected %d", value, inverted[i], index)
+				}
+			}
+
+			if err := net.InvertMapRange(dst, start); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, value := range dst {
+				expected, err := net.InvertMap(start + uint64(i))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expected {
+					t.Fatalf("InvertMapRange inverted %d to %d but InvertMap returned %d", start+uint64(i), value, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	net, err := NewNetwork(100, 9, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := net.MapSlice(make([]uint64, 1), []uint64{1, 2}); !errors.Is(err, ErrDestinationTooShort) {
+		t.Errorf("Expected ErrDestinationTooShort, got %v", err)
+	}
+
+	if err := net.MapSlice(make([]uint64, 2), []uint64{1, 101}); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+
+	if err := net.MapRange(make([]uint64, 10), 95); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+
+	if err := net.MapRange(make([]uint64, 10), 91); err != nil {
+		t.Errorf("Expected range ending at max value to succeed, got %v", err)
+	}
+
+	epochNet, err := NewNetwork(100, 9, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := epochNet.MapRange(make([]uint64, 10), ^uint64(0)-5); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected overflowing range to fail, got %v", err)
+	}
+}
+
+func TestBatchFullRange(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithEpochs()}} {
+		net, err := NewNetwork(math.MaxUint64, 42, 8, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		src := []uint64{0, 7, math.MaxUint64 - 1, math.MaxUint64}
+
+		mapped := make([]uint64, len(src))
+		if err := net.MapSlice(mapped, src); err != nil {
+			t.Fatal(err)
+		}
+
+		inverted := make([]uint64, len(src))
+		if err := net.InvertMapSlice(inverted, mapped); err != nil {
+			t.Fatal(err)
+		}
+
+		ranged := make([]uint64, 4)
+		if err := net.MapRange(ranged, math.MaxUint64-3); err != nil {
+			t.Fatal(err)
+		}
+
+		for i, index := range src {
+			expected, err := net.Map(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mapped[i] != expected {
+				t.Errorf("MapSlice mapped %d to %d but Map returned %d", index, mapped[i], expected)
+			}
+
+			if inverted[i] != index {
+				t.Errorf("InvertMapSlice inverted %d to %d but expected %d", mapped[i], inverted[i], index)
+			}
+		}
+
+		for i, value := range ranged {
+			index := math.MaxUint64 - 3 + uint64(i)
+
+			expected, err := net.Map(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expected {
+				t.Errorf("MapRange mapped %d to %d but Map returned %d", index, value, expected)
+			}
+		}
+	}
+}
+
+const batchBenchmarkSize = 4096
+
+func BenchmarkMapLoop(b *testing.B) {
+	for _, settings := range buildTestSettings() {
+		b.Run(settings.String(), func(b *testing.B) {
+			net, err := createNetwork(settings, 0)
+			if err != nil {
+				b.Fatalf("Unable to create network with error: %v", err)
+			}
+
+			dst := make([]uint64, batchBenchmarkSize)
+			domainSize := settings.maxValue + 1
+
+			for i := 0; i < b.N; i += batchBenchmarkSize {
+				for j := range dst {
+					index := uint64(i + j)
+					if !settings.epochs {
+						index %= domainSize
+					}
+
+					dst[j], err = net.Map(index)
+					if err != nil {
+						b.Fatalf("Failed mapping with error %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMapRange(b *testing.B) {
+	for _, settings := range buildTestSettings() {
+		b.Run(settings.String(), func(b *testing.B) {
+			net, err := createNetwork(settings, 0)
+			if err != nil {
+				b.Fatalf("Unable to create network with error: %v", err)
+			}
+
+			dst := make([]uint64, batchBenchmarkSize)
+			domainSize := settings.maxValue + 1
+
+			for i := 0; i < b.N; i += batchBenchmarkSize {
+				if settings.epochs {
+					err = net.MapRange(dst, uint64(i))
+				} else {
+					// Without epochs fill the batch one domain at a time
+					for j := 0; j < len(dst); j += int(domainSize) {
+						err = net.MapRange(dst[j:min(len(dst), j+int(domainSize))], 0)
+					}
+				}
+
+				if err != nil {
+					b.Fatalf("Failed mapping with error %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package feistel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrUnsupportedOption is returned when an option can't be used with the kind of network being created
+var ErrUnsupportedOption = errors.New("feistel: option is not supported by this network")
+
+// maxBigFactorSearch bounds how many candidates findBigFactors tries, with domains this large the excess of
+// the best candidate near the square root is already negligible
+const maxBigFactorSearch = 1 << 12
+
+// BigNetwork is a Network for domains that don't fit in a uint64, like the IPv6 address space.
+// When max value fits in a uint64 it produces exactly the same permutation as Network with the same
+// seed, rounds and options, including for indices past 2^64 with WithEpochs (compare with MapEpoch).
+// When a half or a radix is wider than 64 bits the round function is run over each 64 bit word of the half
+// and again to expand the result past the radix, so the configured RoundFunc keys every round either way.
+type BigNetwork struct {
+	base       *Network
+	maxValue   *big.Int
+	domainSize *big.Int
+	leftRadix  *big.Int
+	rightRadix *big.Int
+
+	// fits is set when the domain fits in a uint64 and base can do all the work
+	fits bool
+}
+
+var errBigBoundarySpacing = fmt.Errorf("%w, WithEpochBoundarySpacing can't be used with a BigNetwork", ErrUnsupportedOption)
+
+var errBigWalkLimit = fmt.Errorf("%w, WithMaxWalks and WithConstantTime can't be used with a BigNetwork wider than 64 bits", ErrUnsupportedOption)
+
+var errBigExact = fmt.Errorf("%w, WithExactRadices and WithExactShuffle can't be used with a BigNetwork wider than 64 bits", ErrUnsupportedOption)
+
+// NewBigNetwork creates a new BigNetwork, maxValue is the maximum value in the sequence
+// and seed, rounds and opts work the same as in NewNetwork except for WithEpochBoundarySpacing which isn't supported.
+// WithMaxWalks, WithConstantTime, WithExactRadices and WithExactShuffle are only supported when max value fits in a uint64.
+// With WithEpochs an index past 2^64 that hits the walk limit returns an error matching ErrWalkLimitExceeded
+// but not a *WalkLimitError, since it can't hold the index.
+func NewBigNetwork(maxValue *big.Int, seed uint64, rounds uint8, opts ...Option) (*BigNetwork, error) {
+	if maxValue.Sign() < 0 {
+		return nil, fmt.Errorf("%w, minValue: 0, maxValue: %v", ErrMinGreaterThanMax, maxValue)
+	}
+
+	fits := maxValue.IsUint64()
+	baseMax := ^uint64(0)
+	if fits {
+		baseMax = maxValue.Uint64()
+	}
+
+	// The base network would fail on its own max value for WithExactShuffle, so check the options before creating it
+	if !fits {
+		probe := &Network{}
+		for _, opt := range opts {
+			opt(probe)
+		}
+
+		if probe.exactRadices || probe.exactShuffle {
+			return nil, errBigExact
+		}
+	}
+
+	base, err := NewNetwork(baseMax, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if base.boundarySpacing != 0 {
+		return nil, errBigBoundarySpacing
+	}
+
+	// WalkLimitError can't hold an index wider than 64 bits
+	if !fits && base.maxWalks != 0 {
+		return nil, errBigWalkLimit
+	}
+
+	network := &BigNetwork{
+		base:       base,
+		maxValue:   new(big.Int).Set(maxValue),
+		domainSize: new(big.Int).Add(maxValue, big.NewInt(1)),
+		fits:       fits,
+	}
+
+	if fits {
+		network.leftRadix = new(big.Int).SetUint64(base.leftRadix)
+		network.rightRadix = new(big.Int).SetUint64(base.rightRadix)
+	} else {
+		network.leftRadix, network.rightRadix = findBigFactors(network.domainSize)
+	}
+
+	return network, nil
+}
+
+// Map takes an index in a sequence and maps it to another index in the same sequence
+func (n *BigNetwork) Map(index *big.Int) (*big.Int, error) {
+	return n.encode(index, false)
+}
+
+// InvertMap performs an inversion of Map
+func (n *BigNetwork) InvertMap(index *big.Int) (*big.Int, error) {
+	return n.encode(index, true)
+}
+
+func (n *BigNetwork) encode(index *big.Int, invert bool) (*big.Int, error) {
+	if index.Sign() < 0 {
+		return nil, fmt.Errorf("%w, index: %v, minValue: 0", ErrIndexLessThanMinValue, index)
+	}
+
+	if n.fits && index.IsUint64() {
+		value, err := n.base.encode(index.Uint64(), 0, invert)
+		if err != nil {
+			return nil, err
+		}
+
+		return new(big.Int).SetUint64(value), nil
+	}
+
+	epochStart := new(big.Int)
+	offset := new(big.Int).Set(index)
+
+	if index.Cmp(n.maxValue) > 0 {
+		if !n.base.epochs {
+			return nil, fmt.Errorf("%w, index: %v, maxSize: %v", ErrIndexGreatThanMaxValue, index, n.maxValue)
+		}
+
+		epoch := new(big.Int)
+		epoch.QuoRem(index, n.domainSize, offset)
+		epochStart.Mul(epoch, n.domainSize)
+	}
+
+	keyOffset := bigEpochHash(epochStart)
+
+	var mapped *big.Int
+	switch {
+	case n.maxValue.Sign() == 0:
+		mapped = new(big.Int)
+	case n.fits:
+		value, err := n.base.cycleWalk(offset.Uint64(), keyOffset, invert, nil)
+		if err != nil {
+			// Only indices past 2^64 get here and a *WalkLimitError can't hold them
+			var walkErr *WalkLimitError
+			if errors.As(err, &walkErr) {
+				return nil, fmt.Errorf("%w, index: %v, steps: %d", ErrWalkLimitExceeded, index, walkErr.Steps)
+			}
+			return nil, err
+		}
+		mapped = new(big.Int).SetUint64(value)
+	default:
+		mapped = n.cycleWalk(offset, keyOffset, invert)
+	}
+
+	return mapped.Add(mapped, epochStart), nil
+}
+
+// cycleWalk is Network.cycleWalk with big halves
+func (n *BigNetwork) cycleWalk(index *big.Int, keyOffset uint64, invert bool) *big.Int {
+	a := new(big.Int)
+	b := new(big.Int)
+	b.QuoRem(index, n.leftRadix, a)
+
+	start := 0
+	adjust := 1
+
+	if invert {
+		start = n.base.rounds - 1
+		adjust = -1
+	}
+
+	for {
+		for round := start; round >= 0 && round < n.base.rounds; round += adjust {
+			seed := n.base.roundKey(round, keyOffset)
+
+			if round%2 == 0 {
+				f := n.round(seed, b, n.leftRadix)
+				if invert {
+					a.Sub(a, f)
+				} else {
+					a.Add(a, f)
+				}
+				a.Mod(a, n.leftRadix)
+			} else {
+				f := n.round(seed, a, n.rightRadix)
+				if invert {
+					b.Sub(b, f)
+				} else {
+					b.Add(b, f)
+				}
+				b.Mod(b, n.rightRadix)
+			}
+		}
+
+		result := new(big.Int).Mul(b, n.leftRadix)
+		result.Add(result, a)
+
+		if result.Cmp(n.maxValue) <= 0 {
+			return result
+		}
+	}
+}
+
+// round uses the round function of the network directly when both value and radix fit in a uint64
+func (n *BigNetwork) round(key uint64, value, radix *big.Int) *big.Int {
+	if value.IsUint64() && radix.IsUint64() {
+		return new(big.Int).SetUint64(n.base.roundFunc.Round(key, value.Uint64(), radix.Uint64()))
+	}
+
+	return wideHash(n.base.roundFunc, key, value, radix)
+}
+
+// wideHash hashes value with key into the range [0, radix) for values and radices wider than 64 bits.
+// The words of value are absorbed one at a time by chaining them through roundFunc and the result is expanded
+// to 64 bits more than the radix with roundFunc keyed by the chained hash, so the bias of the final modulo is negligible.
+func wideHash(roundFunc RoundFunc, key uint64, value, radix *big.Int) *big.Int {
+	in := make([]byte, (value.BitLen()+63)/64*8)
+	value.FillBytes(in)
+
+	hash := roundFunc.Round(key, uint64(len(in)), math.MaxUint64)
+	for i := 0; i < len(in); i += 8 {
+		hash = roundFunc.Round(key, hash^binary.BigEndian.Uint64(in[i:]), math.MaxUint64)
+	}
+
+	out := make([]byte, ((radix.BitLen()+63)/64+1)*8)
+	for i := 0; i < len(out); i += 8 {
+		binary.BigEndian.PutUint64(out[i:], roundFunc.Round(hash, uint64(i), math.MaxUint64))
+	}
+
+	result := new(big.Int).SetBytes(out)
+	return result.Mod(result, radix)
+}
+
+// bigEpochHash is Network.epochHash for an epoch start of any size, the 64 bit words of the start are folded
+// in from the most significant one down so starts below 2^128 hash the same as they do in Network
+func bigEpochHash(epochStart *big.Int) uint64 {
+	if epochStart.Sign() == 0 {
+		return 0
+	}
+
+	words := make([]byte, (epochStart.BitLen()+63)/64*8)
+	epochStart.FillBytes(words)
+
+	var hash uint64
+	for i := 0; i < len(words)-8; i += 8 {
+		hash = splitmix64(hash ^ binary.BigEndian.Uint64(words[i:]))
+	}
+
+	return splitmix64(binary.BigEndian.Uint64(words[len(words)-8:]) ^ hash)
+}
+
+// findBigFactors is findFactors for values that don't fit in a uint64
+func findBigFactors(value *big.Int) (*big.Int, *big.Int) {
+	sqrt := new(big.Int).Sqrt(value)
+	current := new(big.Int).Set(sqrt)
+
+	var bestValue, bestOther, exceeds *big.Int
+
+	one := big.NewInt(1)
+	other := new(big.Int)
+	remainder := new(big.Int)
+
+	for range maxBigFactorSearch {
+		other.QuoRem(value, current, remainder)
+		if remainder.Sign() == 0 {
+			return current, other
+		}
+
+		other.Add(other, one)
+		diff := new(big.Int).Mul(other, current)
+		diff.Sub(diff, value)
+		diff.Add(diff, new(big.Int).Abs(new(big.Int).Sub(other, current)))
+
+		if bestValue == nil || exceeds.Cmp(diff) > 0 {
+			exceeds = diff
+			bestOther = new(big.Int).Set(other)
+			bestValue = new(big.Int).Set(current)
+		}
+
+		current.Sub(current, one)
+	}
+
+	return bestValue, bestOther
+}
@@ -0,0 +1,259 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBigNetworkMatchesNetwork(t *testing.T) {
+	for _, maxValue := range []uint64{0, 13, 1000, 50_000} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8, WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bigNet, err := NewBigNetwork(new(big.Int).SetUint64(maxValue), 42, 8, WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Force the big code path so it's checked against Network too
+			slowNet, err := NewBigNetwork(new(big.Int).SetUint64(maxValue), 42, 8, WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+			slowNet.fits = false
+
+			for index, value := range net.Range(0, 3*maxValue+2) {
+				for _, candidate := range []*BigNetwork{bigNet, slowNet} {
+					mapped, err := candidate.Map(new(big.Int).SetUint64(index))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if !mapped.IsUint64() || mapped.Uint64() != value {
+						t.Fatalf("Expected %d to map to %d but got %v", index, value, mapped)
+					}
+
+					inverted, err := candidate.InvertMap(mapped)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if !inverted.IsUint64() || inverted.Uint64() != index {
+						t.Fatalf("Mapped %d to %v and inversion produced %v", index, mapped, inverted)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBigNetworkEpochsPastUint64(t *testing.T) {
+	maxValue := uint64(999)
+	net, err := NewNetwork(maxValue, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bigNet, err := NewBigNetwork(new(big.Int).SetUint64(maxValue), 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domainSize := big.NewInt(1000)
+
+	for _, epoch := range []uint64{1 << 60, ^uint64(0)} {
+		view := net.Epoch(epoch)
+		epochStart := new(big.Int).Mul(new(big.Int).SetUint64(epoch), domainSize)
+
+		for offset := range uint64(1000) {
+			expected, err := view.Map(offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			index := new(big.Int).Add(epochStart, new(big.Int).SetUint64(offset))
+			mapped, err := bigNet.Map(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mapped.Sub(mapped, epochStart).Uint64() != expected {
+				t.Fatalf("Expected offset %d of epoch %d to map to %d but got %v", offset, epoch, expected, mapped)
+			}
+		}
+	}
+}
+
+func TestBigNetwork128Bit(t *testing.T) {
+	maxValue := new(big.Int).Lsh(big.NewInt(1), 128)
+	maxValue.Sub(maxValue, big.NewInt(1))
+
+	net, err := NewBigNetwork(maxValue, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]struct{}, 1000)
+
+	for i := range int64(1000) {
+		index := new(big.Int).Sub(maxValue, big.NewInt(i))
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped.Sign() < 0 || mapped.Cmp(maxValue) > 0 {
+			t.Fatalf("Mapped %v to %v which is out of range", index, mapped)
+		}
+
+		if _, ok := seen[mapped.String()]; ok {
+			t.Fatalf("%v was mapped twice", mapped)
+		}
+		seen[mapped.String()] = struct{}{}
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted.Cmp(index) != 0 {
+			t.Fatalf("Mapped %v to %v and inversion produced %v", index, mapped, inverted)
+		}
+	}
+}
+
+func TestBigNetworkSmallBigDomainIsPermutation(t *testing.T) {
+	// Just above 2^64 so the halves are hashed with wideHash part of the time
+	maxValue := new(big.Int).Lsh(big.NewInt(1), 70)
+	maxValue.Add(maxValue, big.NewInt(12345))
+
+	net, err := NewBigNetwork(maxValue, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if net.leftRadix.IsUint64() && net.rightRadix.IsUint64() && new(big.Int).Mul(net.leftRadix, net.rightRadix).Cmp(net.domainSize) < 0 {
+		t.Fatalf("Radices %v and %v don't cover the domain", net.leftRadix, net.rightRadix)
+	}
+
+	index := new(big.Int).Mul(maxValue, big.NewInt(3))
+	mapped, err := net.Map(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	epochStart := new(big.Int).Mul(new(big.Int).Add(maxValue, big.NewInt(1)), big.NewInt(2))
+	epochEnd := new(big.Int).Add(epochStart, maxValue)
+	if mapped.Cmp(epochStart) < 0 || mapped.Cmp(epochEnd) > 0 {
+		t.Errorf("Mapped %v to %v which is outside of its epoch", index, mapped)
+	}
+
+	inverted, err := net.InvertMap(mapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inverted.Cmp(index) != 0 {
+		t.Errorf("Mapped %v to %v and inversion produced %v", index, mapped, inverted)
+	}
+}
+
+func TestBigNetworkWideRoundFunc(t *testing.T) {
+	maxValue := new(big.Int).Lsh(big.NewInt(1), 128)
+	maxValue.Sub(maxValue, big.NewInt(1))
+
+	var outputs []*big.Int
+	for _, roundFunc := range []RoundFunc{SplitMix64{}, SipHash24{}, XXH64{}, mustAES(testKey128)} {
+		net, err := NewBigNetwork(maxValue, 42, 8, WithRoundFunc(roundFunc))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mapped, err := net.Map(big.NewInt(12345))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, other := range outputs {
+			if mapped.Cmp(other) == 0 {
+				t.Errorf("%T produced the same 2^128 mapping as another round function: %v", roundFunc, mapped)
+			}
+		}
+		outputs = append(outputs, mapped)
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted.Cmp(big.NewInt(12345)) != 0 {
+			t.Errorf("%T mapped 12345 to %v and inversion produced %v", roundFunc, mapped, inverted)
+		}
+	}
+}
+
+func TestWideHashWithinRadix(t *testing.T) {
+	radix := new(big.Int).Lsh(big.NewInt(1), 100)
+	radix.Sub(radix, big.NewInt(3))
+
+	for i := range int64(1000) {
+		value := new(big.Int).Lsh(big.NewInt(i), 80)
+		if result := wideHash(SplitMix64{}, uint64(i), value, radix); result.Sign() < 0 || result.Cmp(radix) >= 0 {
+			t.Fatalf("wideHash returned %v for radix %v", result, radix)
+		}
+	}
+}
+
+func TestBigNetworkErrors(t *testing.T) {
+	if _, err := NewBigNetwork(big.NewInt(-1), 42, 8); !errors.Is(err, ErrMinGreaterThanMax) {
+		t.Errorf("Expected ErrMinGreaterThanMax, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(big.NewInt(100), 42, 8, WithEpochBoundarySpacing(1)); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption, got %v", err)
+	}
+
+	wide := new(big.Int).Lsh(big.NewInt(1), 80)
+
+	if _, err := NewBigNetwork(wide, 42, 8, WithMaxWalks(4)); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for WithMaxWalks, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(wide, 42, 8, WithConstantTime(4)); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for WithConstantTime, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(wide, 42, 8, WithExactRadices()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for WithExactRadices, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(wide, 42, 8, WithExactShuffle()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for WithExactShuffle, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(big.NewInt(1000), 42, 8, WithExactRadices(), WithExactShuffle()); err != nil {
+		t.Errorf("Expected exact options to be supported when max value fits in a uint64, got %v", err)
+	}
+
+	if _, err := NewBigNetwork(big.NewInt(100), 42, 8, WithMaxWalks(4)); err != nil {
+		t.Errorf("Expected WithMaxWalks to be supported when max value fits in a uint64, got %v", err)
+	}
+
+	net, err := NewBigNetwork(big.NewInt(100), 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.Map(big.NewInt(-1)); !errors.Is(err, ErrIndexLessThanMinValue) {
+		t.Errorf("Expected ErrIndexLessThanMinValue, got %v", err)
+	}
+
+	if _, err := net.Map(big.NewInt(101)); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package feistel
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// maxBranches is the most branches WithExactRadices splits a domain into, every branch is only updated once
+// every k rounds so more branches would need more rounds to mix
+const maxBranches = 4
+
+// WithExactRadices is an option that removes cycle walking for domain sizes findFactors can't split exactly
+// into two radices. maxValue + 1 is factored and the prime factors are grouped into 3 or 4 radices whose product
+// is exactly the domain size, then every round adds a hash of all the other branches to one branch, in turn.
+// It's only used when no radix is larger than the product of the others, so every branch is updated from at least
+// as many values as it can hold, otherwise the network is the same as without the option. That means cycle walking
+// is only removed for composite sizes without a dominant prime factor: primes and sizes like 2·p or 6·p for a large
+// prime p still cycle walk, since a branch of 2 or 6 values could only ever shift the branch of p by that many amounts.
+// Domains that findFactors already splits exactly, like powers of 2, are never changed. With k branches each branch
+// is updated once every k rounds so use at least 2k rounds.
+func WithExactRadices() Option {
+	return func(m *Network) {
+		m.exactRadices = true
+	}
+}
+
+// findExactRadices returns the radices for WithExactRadices or nil if the domain should use two radices
+func findExactRadices(maxValue uint64) []uint64 {
+	if maxValue < 3 || maxValue == ^uint64(0) {
+		return nil
+	}
+
+	domainSize := maxValue + 1
+	primes := primeFactors(domainSize)
+
+	for k := 3; k <= min(maxBranches, len(primes)); k++ {
+		radices := groupFactors(primes, k)
+
+		// radix^2 <= domainSize means the radix is no larger than the product of the other radices
+		largest := slices.Max(radices)
+		if hi, lo := bits.Mul64(largest, largest); hi == 0 && lo <= domainSize {
+			return radices
+		}
+	}
+
+	return nil
+}
+
+// groupFactors puts primes into k groups with products as close as possible, largest prime first into the
+// group with the smallest product. primes has to be sorted from smallest to largest.
+func groupFactors(primes []uint64, k int) []uint64 {
+	radices := make([]uint64, k)
+	for i := range radices {
+		radices[i] = 1
+	}
+
+	for i := len(primes) - 1; i >= 0; i-- {
+		smallest := 0
+		for j, radix := range radices {
+			if radix < radices[smallest] {
+				smallest = j
+			}
+		}
+		radices[smallest] *= primes[i]
+	}
+
+	return radices
+}
+
+// runBranches maps index with the k branch network, there's no cycle walking since the radices multiply
+// to exactly the domain size
+func (n *Network) runBranches(index, keyOffset uint64, invert bool) uint64 {
+	var digits [maxBranches]uint64
+	k := len(n.radices)
+
+	for i, radix := range n.radices {
+		digits[i] = index % radix
+		index /= radix
+	}
+
+	start := 0
+	adjust := 1
+
+	if invert {
+		start = n.rounds - 1
+		adjust = -1
+	}
+
+	for round := start; round >= 0 && round < n.rounds; round += adjust {
+		target := round % k
+		radix := n.radices[target]
+
+		// The other branches read as a single mixed radix number
+		var source uint64
+		for i := k - 1; i >= 0; i-- {
+			if i != target {
+				source = source*n.radices[i] + digits[i]
+			}
+		}
+
+		f := n.roundFunc.Round(n.roundKey(round, keyOffset), source, radix)
+		if invert {
+			digits[target] = (digits[target] + radix - f) % radix
+		} else {
+			digits[target] = (digits[target] + f) % radix
+		}
+	}
+
+	var result uint64
+	for i := k - 1; i >= 0; i-- {
+		result = result*n.radices[i] + digits[i]
+	}
+
+	return result
+}
+
+// primeFactors returns the prime factors of n from smallest to largest, repeated as often as they divide n
+func primeFactors(n uint64) []uint64 {
+	var factors []uint64
+
+	for _, p := range []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+
+	var split func(n uint64)
+	split = func(n uint64) {
+		if n == 1 {
+			return
+		}
+
+		if isPrime(n) {
+			factors = append(factors, n)
+			return
+		}
+
+		d := pollardRho(n)
+		split(d)
+		split(n / d)
+	}
+	split(n)
+
+	slices.Sort(factors)
+	return factors
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+func powMod(base, exp, m uint64) uint64 {
+	result := uint64(1)
+	base %= m
+
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result = mulMod(result, base, m)
+		}
+		base = mulMod(base, base, m)
+	}
+
+	return result
+}
+
+// isPrime is a Miller-Rabin test with the bases that make it deterministic for every uint64
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+
+	d := n - 1
+	s := 0
+	for d%2 == 0 {
+		d /= 2
+		s++
+	}
+
+	for _, a := range []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		if a%n == 0 {
+			return true
+		}
+
+		x := powMod(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+
+		composite := true
+		for range s - 1 {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+
+		if composite {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pollardRho returns a non trivial factor of the odd composite n
+func pollardRho(n uint64) uint64 {
+	for c := uint64(1); ; c++ {
+		x, y, d := uint64(2), uint64(2), uint64(1)
+
+		next := func(v uint64) uint64 {
+			v, carry := bits.Add64(mulMod(v, v, n), c, 0)
+			if carry != 0 || v >= n {
+				v -= n
+			}
+			return v
+		}
+
+		for d == 1 {
+			x = next(x)
+			y = next(next(y))
+
+			if x > y {
+				d = gcd(x-y, n)
+			} else {
+				d = gcd(y-x, n)
+			}
+		}
+
+		if d != n {
+			return d
+		}
+	}
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
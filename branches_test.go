@@ -0,0 +1,174 @@
+package feistel
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+type countingRoundFunc struct {
+	calls *int
+}
+
+func (c countingRoundFunc) Round(key, value, radix uint64) uint64 {
+	*c.calls++
+	return SplitMix64{}.Round(key, value, radix)
+}
+
+func TestExactRadicesIsPermutation(t *testing.T) {
+	for _, maxValue := range []uint64{1000, 2430, 47026} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			calls := 0
+			net, err := NewNetwork(maxValue, 42, 8, WithExactRadices(), WithEpochs(), WithRoundFunc(countingRoundFunc{&calls}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(net.radices) < 3 {
+				t.Fatalf("Expected at least 3 radices for %d, got %v", maxValue+1, net.radices)
+			}
+
+			seen := make(map[uint64]struct{}, maxValue+1)
+			for index := range maxValue + 1 {
+				calls = 0
+				mapped, err := net.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if calls != 8 {
+					t.Fatalf("Expected exactly 8 rounds without cycle walking, got %d", calls)
+				}
+
+				if _, ok := seen[mapped]; ok || mapped > maxValue {
+					t.Fatalf("Mapped %d to %d which was either seen before or out of range", index, mapped)
+				}
+				seen[mapped] = struct{}{}
+
+				inverted, err := net.InvertMap(mapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if inverted != index {
+					t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+				}
+			}
+
+			epochIndex := 3*(maxValue+1) + 17
+			mapped, err := net.Map(epochIndex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if inverted, err := net.InvertMap(mapped); err != nil || inverted != epochIndex {
+				t.Errorf("Mapped %d to %d and inversion produced %d, %v", epochIndex, mapped, inverted, err)
+			}
+		})
+	}
+}
+
+func TestFourBranches(t *testing.T) {
+	net, err := NewNetwork(2*3*5*7-1, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.radices = []uint64{2, 3, 5, 7}
+
+	seen := make(map[uint64]struct{}, 210)
+	for index := range uint64(210) {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := seen[mapped]; ok || mapped >= 210 {
+			t.Fatalf("Mapped %d to %d which was either seen before or out of range", index, mapped)
+		}
+		seen[mapped] = struct{}{}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != index {
+			t.Fatalf("Mapped %d to %d and inversion produced %d, %v", index, mapped, inverted, err)
+		}
+	}
+}
+
+func TestExactRadicesFallsBack(t *testing.T) {
+	// Prime sizes, sizes with one large prime factor like 2·p and 6·p and sizes findFactors already splits exactly
+	for _, maxValue := range []uint64{12, 100, 102, 1023, 49_999, 2*1_000_003 - 1, 6*1_000_003 - 1, 1<<32 - 1, ^uint64(0)} {
+		net, err := NewNetwork(maxValue, 42, 8, WithExactRadices())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if net.radices != nil {
+			t.Errorf("Expected %d to keep two radices, got %v", maxValue+1, net.radices)
+			continue
+		}
+
+		plain, err := NewNetwork(maxValue, 42, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, index := range []uint64{0, 1, maxValue / 2, maxValue} {
+			expected, _ := plain.Map(index)
+			if mapped, _ := net.Map(index); mapped != expected {
+				t.Errorf("Expected %d to map the same as without the option for %d, got %d and %d", index, maxValue, mapped, expected)
+			}
+		}
+	}
+}
+
+func TestFindExactRadices(t *testing.T) {
+	tests := []struct {
+		domainSize uint64
+		expected   []uint64
+	}{
+		{1001, []uint64{7, 11, 13}},
+		{2431, []uint64{11, 13, 17}},
+		{30, []uint64{2, 3, 5}},
+		{2 * 1_000_003, nil},
+		{1_000_003, nil},
+	}
+
+	for _, test := range tests {
+		radices := findExactRadices(test.domainSize - 1)
+		slices.Sort(radices)
+
+		if !slices.Equal(radices, test.expected) {
+			t.Errorf("Expected %d to be split into %v, got %v", test.domainSize, test.expected, radices)
+		}
+	}
+}
+
+func TestPrimeFactors(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	values := []uint64{1, 2, 97, 1 << 63, 4_294_967_291 * 4_294_967_279, 18_446_744_073_709_551_557, ^uint64(0)}
+	for range 200 {
+		values = append(values, rng.Uint64())
+	}
+
+	for _, value := range values {
+		factors := primeFactors(value)
+
+		product := uint64(1)
+		for _, factor := range factors {
+			if !isPrime(factor) {
+				t.Fatalf("Factor %d of %d isn't prime", factor, value)
+			}
+			product *= factor
+		}
+
+		if product != value {
+			t.Fatalf("Factors %v don't multiply to %d", factors, value)
+		}
+	}
+
+	for n, expected := range map[uint64]bool{0: false, 1: false, 2: true, 4: false, 561: false, 3_215_031_751: false, 1_000_000_007: true} {
+		if isPrime(n) != expected {
+			t.Errorf("Expected isPrime(%d) to be %t", n, expected)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package codes
+
+import "errors"
+
+// ErrExhausted is returned when every code of a Generator has been handed out
+var ErrExhausted = errors.New("codes: all codes have been generated")
+
+// Batch hands out codes for consecutive counters, save Counter() and pass it to NewBatch to resume
+// without ever repeating a code
+type Batch struct {
+	generator *Generator
+	counter   uint64
+}
+
+// NewBatch creates a Batch that starts at counter
+func (g *Generator) NewBatch(counter uint64) *Batch {
+	return &Batch{generator: g, counter: counter}
+}
+
+// Counter returns the counter of the next code
+func (b *Batch) Counter() uint64 {
+	return b.counter
+}
+
+// Next returns the next code
+func (b *Batch) Next() (string, error) {
+	if b.counter >= b.generator.capacity {
+		return "", ErrExhausted
+	}
+
+	code, err := b.generator.Code(b.counter)
+	if err != nil {
+		return "", err
+	}
+
+	b.counter++
+	return code, nil
+}
+
+// Fill writes the next len(dst) codes into dst, if it runs out of codes the ones it did write are kept
+// and Counter() points past them
+func (b *Batch) Fill(dst []string) error {
+	for i := range dst {
+		code, err := b.Next()
+		if err != nil {
+			return err
+		}
+		dst[i] = code
+	}
+
+	return nil
+}
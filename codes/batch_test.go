@@ -0,0 +1,59 @@
+package codes
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchResume(t *testing.T) {
+	g := newGenerator(t, 6, WithGroups(3))
+
+	all := make([]string, 1000)
+	if err := g.NewBatch(0).Fill(all); err != nil {
+		t.Fatal(err)
+	}
+
+	first := g.NewBatch(0)
+	part := make([]string, 400)
+	if err := first.Fill(part); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := first.Counter()
+	if saved != 400 {
+		t.Fatalf("Expected the counter to be 400, got %d", saved)
+	}
+
+	resumed := g.NewBatch(saved)
+	rest := make([]string, 600)
+	if err := resumed.Fill(rest); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, code := range append(part, rest...) {
+		if code != all[i] {
+			t.Fatalf("Expected code %d to be %q after resuming, got %q", i, all[i], code)
+		}
+	}
+}
+
+func TestBatchExhausted(t *testing.T) {
+	g := newGenerator(t, 2, WithoutCheckCharacter(), WithAlphabet("ABC"))
+
+	b := g.NewBatch(5)
+	dst := make([]string, 10)
+
+	if err := b.Fill(dst); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("Expected ErrExhausted, got %v", err)
+	}
+
+	if b.Counter() != 9 {
+		t.Errorf("Expected the counter to stop at 9, got %d", b.Counter())
+	}
+
+	for i, code := range dst[:4] {
+		if code == "" {
+			t.Errorf("Expected code %d to be written before running out", i)
+		}
+	}
+}
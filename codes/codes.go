@@ -0,0 +1,273 @@
+// Package codes generates unique coupon and voucher codes from a counter
+// Codes use a human friendly alphabet, can be grouped with dashes and end with a check character
+// that catches any single mistyped character and any two swapped characters
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mormehtar/feistel"
+)
+
+// DefaultAlphabet leaves out 0, O, 1, I and U so there are 31 characters, a prime number of them is what lets
+// the check character catch every transposition
+const DefaultAlphabet = "23456789ABCDEFGHJKLMNPQRSTVWXYZ"
+
+// ErrInvalidAlphabet is returned when the alphabet repeats a character, isn't ASCII, or with a check character
+// when its size isn't a prime number larger than the code length
+var ErrInvalidAlphabet = errors.New("codes: invalid alphabet")
+
+// ErrInvalidLength is returned when a code has the wrong number of characters, or when the code length is too long
+// for the counter to fit in a uint64
+var ErrInvalidLength = errors.New("codes: invalid length")
+
+// ErrInvalidCharacter is returned when a code has a character that isn't in the alphabet
+var ErrInvalidCharacter = errors.New("codes: invalid character")
+
+// ErrCheckCharacter is returned when the check character doesn't match the rest of the code
+var ErrCheckCharacter = errors.New("codes: check character doesn't match")
+
+// Generator turns counters into codes and back
+type Generator struct {
+	network   *feistel.Network
+	capacity  uint64
+	alphabet  string
+	lookup    [256]int16
+	length    int
+	groupSize int
+	check     bool
+	foldCase  bool
+}
+
+// Option is an optional setting for a Generator
+type Option func(*Generator)
+
+// WithAlphabet replaces DefaultAlphabet, with a check character the size of the alphabet has to be a prime number
+func WithAlphabet(alphabet string) Option {
+	return func(g *Generator) {
+		g.alphabet = alphabet
+	}
+}
+
+// WithGroups puts a dash after every size characters, so a 12 character code with groups of 4 looks like ABCD-EFGH-JKLM
+func WithGroups(size int) Option {
+	return func(g *Generator) {
+		g.groupSize = size
+	}
+}
+
+// WithoutCheckCharacter leaves off the check character
+func WithoutCheckCharacter() Option {
+	return func(g *Generator) {
+		g.check = false
+	}
+}
+
+// NewGenerator creates a Generator for codes of length random characters plus the check character.
+// The codes are mapped by a keyed network so they can't be guessed from each other without the key,
+// see feistel.NewKeyedNetwork for key and rounds.
+func NewGenerator(length int, key []byte, rounds uint8, opts ...Option) (*Generator, error) {
+	g := &Generator{
+		alphabet: DefaultAlphabet,
+		length:   length,
+		check:    true,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if err := g.buildLookup(); err != nil {
+		return nil, err
+	}
+
+	radix := uint64(len(g.alphabet))
+
+	if g.check && (!isPrime(radix) || uint64(length)+1 >= radix) {
+		return nil, fmt.Errorf("%w, a check character needs a prime number of characters larger than the length + 1, got %d", ErrInvalidAlphabet, radix)
+	}
+
+	maxValue := uint64(1)
+	for range length {
+		if maxValue > ^uint64(0)/radix {
+			return nil, fmt.Errorf("%w, %d characters from an alphabet of %d don't fit in a uint64", ErrInvalidLength, length, radix)
+		}
+		maxValue *= radix
+	}
+
+	if length < 1 || maxValue < 2 {
+		return nil, fmt.Errorf("%w, length: %d", ErrInvalidLength, length)
+	}
+
+	network, err := feistel.NewKeyedNetwork(maxValue-1, key, rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	g.network = network
+	g.capacity = maxValue
+	return g, nil
+}
+
+func (g *Generator) buildLookup() error {
+	if len(g.alphabet) < 2 {
+		return fmt.Errorf("%w, alphabet: %q", ErrInvalidAlphabet, g.alphabet)
+	}
+
+	for i := range g.lookup {
+		g.lookup[i] = -1
+	}
+
+	for i := range len(g.alphabet) {
+		c := g.alphabet[i]
+		if c >= 0x80 || c == '-' || g.lookup[c] != -1 {
+			return fmt.Errorf("%w, %q is repeated, a dash or not ASCII", ErrInvalidAlphabet, c)
+		}
+		g.lookup[c] = int16(i)
+	}
+
+	// Codes get typed in by people, so lower case is accepted when it can't be confused with anything
+	g.foldCase = g.alphabet == strings.ToUpper(g.alphabet)
+
+	return nil
+}
+
+// Capacity returns the number of unique codes, counters go from 0 to Capacity() - 1
+func (g *Generator) Capacity() uint64 {
+	return g.capacity
+}
+
+// Code returns the code for counter
+func (g *Generator) Code(counter uint64) (string, error) {
+	value, err := g.network.Map(counter)
+	if err != nil {
+		return "", err
+	}
+
+	radix := uint64(len(g.alphabet))
+	digits := make([]int, g.length, g.length+1)
+
+	for i := g.length - 1; i >= 0; i-- {
+		digits[i] = int(value % radix)
+		value /= radix
+	}
+
+	if g.check {
+		digits = append(digits, g.checkDigit(digits))
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if g.groupSize > 0 && i > 0 && i%g.groupSize == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(g.alphabet[digit])
+	}
+
+	return b.String(), nil
+}
+
+// Counter performs an inversion of Code
+func (g *Generator) Counter(code string) (uint64, error) {
+	digits, err := g.parse(code)
+	if err != nil {
+		return 0, err
+	}
+
+	radix := uint64(len(g.alphabet))
+	var value uint64
+
+	for _, digit := range digits[:g.length] {
+		value = value*radix + uint64(digit)
+	}
+
+	return g.network.InvertMap(value)
+}
+
+// Validate checks that code has the right length, only uses the alphabet and has the right check character.
+// It doesn't need the key, a valid code can still be one that was never issued.
+func (g *Generator) Validate(code string) error {
+	_, err := g.parse(code)
+	return err
+}
+
+func (g *Generator) parse(code string) ([]int, error) {
+	expected := g.length
+	if g.check {
+		expected++
+	}
+
+	digits := make([]int, 0, expected)
+
+	for i := range len(code) {
+		c := code[i]
+		if c == '-' || c == ' ' {
+			continue
+		}
+
+		if g.foldCase && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+
+		digit := g.lookup[c]
+		if digit < 0 {
+			return nil, fmt.Errorf("%w, %q in %q", ErrInvalidCharacter, code[i], code)
+		}
+
+		digits = append(digits, int(digit))
+	}
+
+	if len(digits) != expected {
+		return nil, fmt.Errorf("%w, %q has %d characters, expected %d", ErrInvalidLength, code, len(digits), expected)
+	}
+
+	if g.check && g.checkDigit(digits[:g.length]) != digits[g.length] {
+		return nil, fmt.Errorf("%w, %q", ErrCheckCharacter, code)
+	}
+
+	return digits, nil
+}
+
+// checkDigit returns c so that the sum of (i+1) * digit[i] over all the digits and c is 0 modulo the radix.
+// Every weight is a different non zero number below a prime radix, so changing one digit or swapping two
+// always changes the sum.
+func (g *Generator) checkDigit(digits []int) int {
+	radix := len(g.alphabet)
+	sum := 0
+
+	for i, digit := range digits {
+		sum = (sum + (i+1)*digit) % radix
+	}
+
+	// Solve sum + (length+1) * c = 0 with the inverse of the last weight
+	weight := len(digits) + 1
+	return (radix - sum) * inverse(weight, radix) % radix
+}
+
+// inverse returns the multiplicative inverse of a modulo the prime p
+func inverse(a, p int) int {
+	result := 1
+	for exp := p - 2; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result = result * a % p
+		}
+		a = a * a % p
+	}
+	return result
+}
+
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+
+	for i := uint64(2); i*i <= n; i++ {
+		if n%i == 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func newGenerator(t *testing.T, length int, opts ...Option) *Generator {
+	t.Helper()
+
+	g, err := NewGenerator(length, testKey, 8, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestCodeRoundTrip(t *testing.T) {
+	g := newGenerator(t, 10, WithGroups(4))
+	seen := make(map[string]struct{})
+
+	for counter := range uint64(20000) {
+		code, err := g.Code(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(code) != 13 || code[4] != '-' || code[9] != '-' {
+			t.Fatalf("Expected %d to produce a code like XXXX-XXXX-XXX, got %q", counter, code)
+		}
+
+		if strings.ContainsAny(code, "01OIU") {
+			t.Fatalf("Code %q uses a character that is easy to confuse", code)
+		}
+
+		if _, ok := seen[code]; ok {
+			t.Fatalf("Code %q was generated twice", code)
+		}
+		seen[code] = struct{}{}
+
+		decoded, err := g.Counter(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded != counter {
+			t.Fatalf("Generated %q from %d and decoding produced %d", code, counter, decoded)
+		}
+	}
+}
+
+func TestCheckCharacterCatchesTypos(t *testing.T) {
+	g := newGenerator(t, 12)
+
+	for counter := range uint64(50) {
+		code, err := g.Code(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := g.Validate(code); err != nil {
+			t.Fatalf("Expected %q to be valid, got %v", code, err)
+		}
+
+		for i := range len(code) {
+			for j := range len(DefaultAlphabet) {
+				if DefaultAlphabet[j] == code[i] {
+					continue
+				}
+
+				typo := code[:i] + DefaultAlphabet[j:j+1] + code[i+1:]
+				if err := g.Validate(typo); !errors.Is(err, ErrCheckCharacter) {
+					t.Fatalf("Expected the typo %q of %q to be caught, got %v", typo, code, err)
+				}
+			}
+
+			for j := i + 1; j < len(code); j++ {
+				if code[i] == code[j] {
+					continue
+				}
+
+				swapped := []byte(code)
+				swapped[i], swapped[j] = swapped[j], swapped[i]
+				if err := g.Validate(string(swapped)); !errors.Is(err, ErrCheckCharacter) {
+					t.Fatalf("Expected swapping %d and %d in %q to be caught, got %v", i, j, code, err)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateNormalizes(t *testing.T) {
+	g := newGenerator(t, 8, WithGroups(3))
+
+	code, err := g.Code(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, variant := range []string{strings.ToLower(code), strings.ReplaceAll(code, "-", ""), strings.ReplaceAll(code, "-", " ")} {
+		if counter, err := g.Counter(variant); err != nil || counter != 7 {
+			t.Errorf("Expected %q to decode to 7, got %d, %v", variant, counter, err)
+		}
+	}
+
+	if err := g.Validate(code[:len(code)-1]); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+
+	if err := g.Validate("0" + code[1:]); !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("Expected ErrInvalidCharacter, got %v", err)
+	}
+}
+
+func TestWithoutCheckCharacter(t *testing.T) {
+	g := newGenerator(t, 4, WithoutCheckCharacter(), WithAlphabet("ABCDEFGH"))
+
+	if g.Capacity() != 4096 {
+		t.Fatalf("Expected 8^4 codes, got %d", g.Capacity())
+	}
+
+	seen := make(map[string]struct{})
+	for counter := range g.Capacity() {
+		code, err := g.Code(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(code) != 4 {
+			t.Fatalf("Expected a 4 character code, got %q", code)
+		}
+		seen[code] = struct{}{}
+	}
+
+	if len(seen) != 4096 {
+		t.Errorf("Expected every code to be generated once, got %d unique codes", len(seen))
+	}
+
+	if _, err := g.Code(4096); !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		length   int
+		opts     []Option
+		expected error
+	}{
+		{0, nil, ErrInvalidLength},
+		{13, nil, ErrInvalidLength},
+		{8, []Option{WithAlphabet("ABCDEFGH")}, ErrInvalidAlphabet},
+		{4, []Option{WithAlphabet("ABCDE")}, ErrInvalidAlphabet},
+		{4, []Option{WithAlphabet("ABCA"), WithoutCheckCharacter()}, ErrInvalidAlphabet},
+		{4, []Option{WithAlphabet("AB-"), WithoutCheckCharacter()}, ErrInvalidAlphabet},
+	}
+
+	for _, test := range tests {
+		if _, err := NewGenerator(test.length, testKey, 8, test.opts...); !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for length %d, got %v", test.expected, test.length, err)
+		}
+	}
+
+	if _, err := NewGenerator(8, []byte("short"), 8); !errors.Is(err, feistel.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package feistel
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// MapEpoch maps index within the given epoch and returns the mapped position inside that epoch (between 0 and max value).
+// It's the same permutation WithEpochs uses for the values from epoch * (max value + 1) to epoch * (max value + 1) + max value,
+// but you don't have to fold the epoch into the index yourself so any epoch can be addressed even if
+// epoch * (max value + 1) doesn't fit in a uint64. It doesn't require WithEpochs.
+func (n *Network) MapEpoch(epoch, index uint64) (uint64, error) {
+	return n.Epoch(epoch).Map(index)
+}
+
+// InvertMapEpoch performs an inversion of MapEpoch
+func (n *Network) InvertMapEpoch(epoch, index uint64) (uint64, error) {
+	return n.Epoch(epoch).InvertMap(index)
+}
+
+// EpochOf splits a value from the range covered by WithEpochs into the epoch it belongs to and
+// its offset inside that epoch, so MapEpoch(EpochOf(value)) is Map(value) minus the start of the epoch
+func (n *Network) EpochOf(value uint64) (epoch, offset uint64) {
+	if n.maxValue == ^uint64(0) {
+		return 0, value
+	}
+
+	domainSize := n.maxValue + 1
+	return value / domainSize, value % domainSize
+}
+
+// Epoch returns a view of a single epoch, the epoch hash is computed once so it's
+// the fastest way to map many indices within the same epoch
+func (n *Network) Epoch(epoch uint64) EpochView {
+	return EpochView{
+		network: n,
+		epoch:   epoch,
+		hash:    n.epochHash(epoch),
+	}
+}
+
+// EpochView maps indices within a single epoch of a Network, it's created with Network.Epoch
+type EpochView struct {
+	network *Network
+	epoch   uint64
+	hash    uint64
+}
+
+var _ Mapper = EpochView{}
+
+// Epoch returns the epoch the view maps in
+func (v EpochView) Epoch() uint64 {
+	return v.epoch
+}
+
+// Map takes an index in the epoch and maps it to another index in the same epoch
+func (v EpochView) Map(index uint64) (uint64, error) {
+	return v.encode(index, false)
+}
+
+// InvertMap performs an inversion of Map
+func (v EpochView) InvertMap(index uint64) (uint64, error) {
+	return v.encode(index, true)
+}
+
+func (v EpochView) encode(index uint64, invert bool) (uint64, error) {
+	n := v.network
+
+	if index > n.maxValue {
+		return 0, fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, index, n.maxValue)
+	}
+
+	if n.maxValue == 0 {
+		return 0, nil
+	}
+
+	value, err := n.permute(v.epoch, v.hash, 0, index, invert, nil)
+	if err != nil {
+		return 0, withIndex(err, index)
+	}
+
+	return value, nil
+}
+
+// epochHash returns the value mixed into the round keys of an epoch. It's the hash of the first index in the epoch
+// which keeps it compatible with the values WithEpochs produced before epochs could be addressed directly,
+// once that index no longer fits in 64 bits the high bits are hashed in as well.
+func (n *Network) epochHash(epoch uint64) uint64 {
+	if epoch == 0 {
+		return 0
+	}
+
+	var hi, lo uint64
+	if n.maxValue == ^uint64(0) {
+		hi = epoch
+	} else {
+		hi, lo = bits.Mul64(epoch, n.maxValue+1)
+	}
+
+	if hi == 0 {
+		return splitmix64(lo)
+	}
+
+	return splitmix64(lo ^ splitmix64(hi))
+}
@@ -0,0 +1,135 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMapEpochMatchesEpochs(t *testing.T) {
+	for _, maxValue := range []uint64{1, 13, 1000} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8, WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			domainSize := maxValue + 1
+
+			for value := uint64(0); value < 20*domainSize; value++ {
+				expected, err := net.Map(value)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				epoch, offset := net.EpochOf(value)
+				if epoch*domainSize+offset != value {
+					t.Fatalf("EpochOf(%d) returned epoch %d and offset %d", value, epoch, offset)
+				}
+
+				mapped, err := net.MapEpoch(epoch, offset)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mapped+epoch*domainSize != expected {
+					t.Fatalf("MapEpoch(%d, %d) returned %d but Map(%d) returned %d", epoch, offset, mapped, value, expected)
+				}
+
+				inverted, err := net.InvertMapEpoch(epoch, mapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if inverted != offset {
+					t.Fatalf("Mapped %d to %d in epoch %d and inversion produced %d", offset, mapped, epoch, inverted)
+				}
+			}
+		})
+	}
+}
+
+func TestMapEpochBeyondUint64(t *testing.T) {
+	maxValue := uint64(1<<32 - 1)
+	net, err := NewNetwork(maxValue, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// epoch * 2^32 wraps around to the same 64 bit value for all of these epochs
+	epochs := []uint64{1, 1 + 1<<32, 1 + 2<<32, ^uint64(0)}
+	seen := make(map[uint64]uint64, len(epochs))
+
+	for _, epoch := range epochs {
+		view := net.Epoch(epoch)
+		if view.Epoch() != epoch {
+			t.Fatalf("Expected view of epoch %d, got %d", epoch, view.Epoch())
+		}
+
+		mapped, err := view.Map(12345)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if previous, ok := seen[mapped]; ok {
+			t.Errorf("Epochs %d and %d both map 12345 to %d", previous, epoch, mapped)
+		}
+		seen[mapped] = epoch
+
+		inverted, err := view.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != 12345 {
+			t.Errorf("Mapped 12345 to %d in epoch %d and inversion produced %d", mapped, epoch, inverted)
+		}
+	}
+}
+
+func TestEpochViewIsPermutation(t *testing.T) {
+	net, err := NewNetwork(101, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mapper Mapper = net.Epoch(1 << 60)
+	seen := make(map[uint64]struct{}, 102)
+
+	for index := range uint64(102) {
+		mapped, err := mapper.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := seen[mapped]; ok || mapped > 101 {
+			t.Fatalf("Mapped %d to %d which was either seen before or out of range", index, mapped)
+		}
+		seen[mapped] = struct{}{}
+	}
+
+	if _, err := mapper.Map(102); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
+
+func TestEpochOfFullRange(t *testing.T) {
+	net, err := NewNetwork(^uint64(0), 42, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if epoch, offset := net.EpochOf(^uint64(0)); epoch != 0 || offset != ^uint64(0) {
+		t.Errorf("Expected epoch 0 and offset %d, got %d and %d", ^uint64(0), epoch, offset)
+	}
+
+	view := net.Epoch(1)
+	mapped, err := view.Map(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inverted, err := view.InvertMap(mapped); err != nil || inverted != 7 {
+		t.Errorf("Expected inversion to produce 7, got %d, %v", inverted, err)
+	}
+}
@@ -1,11 +1,13 @@
 // Package feistel is a non cryptographic implementation of the feistel cipher
 // It's useful for creating random permutations of ranges of integers from 0 to n
+// If the permutation has to be unpredictable use NewKeyedNetwork which runs AES in every round
 package feistel
 
 import (
 	"errors"
 	"fmt"
 	"math"
+	"sync/atomic"
 )
 
 // ErrIndexGreatThanMaxValue is returned when your index is greater than the maximum value
@@ -35,7 +37,7 @@ func WithEpochs() Option {
 // seed is a hash seed, by providing a different value this will return different permutations of the sequence
 // rounds is the number of hash rounds the network will run to generate each value, the more rounds you run the
 // better the distribution but it also adds time to running the function
-// opts is a list of all the optional parameters you can add, right now that only includes WithEpochs()
+// opts is a list of all the optional parameters you can add, like WithEpochs() or WithRoundFunc()
 func NewNetwork(maxValue, seed uint64, rounds uint8, opts ...Option) (*Network, error) {
 	l, r := findFactors(maxValue)
 
@@ -54,6 +56,30 @@ func NewNetwork(maxValue, seed uint64, rounds uint8, opts ...Option) (*Network,
 		return nil, ErrRoundsMustBeSet
 	}
 
+	if network.roundFunc == nil {
+		network.roundFunc = SplitMix64{}
+	}
+
+	if network.keySchedule > IndependentEpochKeys {
+		return nil, fmt.Errorf("%w, schedule: %d", ErrUnknownKeySchedule, network.keySchedule)
+	}
+
+	if network.boundarySpacing > maxBoundarySpacing(maxValue) {
+		return nil, fmt.Errorf("%w, spacing: %d, maxValue: %d", ErrBoundarySpacingTooLarge, network.boundarySpacing, maxValue)
+	}
+
+	if network.constantTime && network.maxWalks == 0 {
+		return nil, ErrWalksMustBeSet
+	}
+
+	if network.exactShuffle && maxValue >= MaxShuffleSize {
+		return nil, fmt.Errorf("%w, maxValue: %d, maxSize: %d", ErrShuffleTooLarge, maxValue, MaxShuffleSize-1)
+	}
+
+	if network.exactRadices && l*r != maxValue+1 {
+		network.radices = findExactRadices(maxValue)
+	}
+
 	network.seeds = make([]uint64, network.rounds)
 
 	currentSeed := seed
@@ -62,6 +88,18 @@ func NewNetwork(maxValue, seed uint64, rounds uint8, opts ...Option) (*Network,
 		network.seeds[i] = currentSeed
 	}
 
+	// The iterators can't report a *WalkLimitError so they walk without the limit, which gives the same permutation
+	if network.maxWalks != 0 {
+		unbounded, err := NewNetwork(maxValue, seed, rounds, append(opts[:len(opts):len(opts)], withoutWalkLimit())...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Share the seeds so NewKeyedNetwork replacing them applies to both
+		unbounded.seeds = network.seeds
+		network.unbounded = unbounded
+	}
+
 	return network, nil
 }
 
@@ -72,77 +110,185 @@ type Network struct {
 	epochs   bool
 	seeds    []uint64
 
+	roundFunc RoundFunc
+
+	boundarySpacing uint64
+	swapTables      [swapCacheSize]atomic.Pointer[swapTable]
+	swapNext        atomic.Uint32
+	keySchedule     EpochKeySchedule
+
+	// radices is only set by WithExactRadices when the domain is split into more than two branches
+	exactRadices bool
+	radices      []uint64
+
+	maxWalks     uint64
+	constantTime bool
+	unbounded    *Network
+
+	exactShuffle  bool
+	shuffleTables [shuffleCacheSize]atomic.Pointer[shuffleTable]
+	shuffleNext   atomic.Uint32
+
 	leftRadix  uint64
 	rightRadix uint64
 }
 
+// Mapper is a bijection over the integers from 0 to some max value. Network implements it and so do
+// the adapters in the subpackages, accept a Mapper if you don't care how the permutation is generated
+type Mapper interface {
+	Map(index uint64) (uint64, error)
+	InvertMap(index uint64) (uint64, error)
+}
+
+var _ Mapper = (*Network)(nil)
+
 // Map takes an index in a sequence and maps it to another index in the same sequence
 func (n *Network) Map(index uint64) (uint64, error) {
-	return n.encode(index, false)
+	return n.encode(index, 0, false)
 }
 
 // InvertMap performs an inversion of Map
 func (n *Network) InvertMap(index uint64) (uint64, error) {
-	return n.encode(index, true)
+	return n.encode(index, 0, true)
 }
 
-func (n *Network) encode(index uint64, invert bool) (uint64, error) {
+// encode maps or inverts index, tweakHash is mixed into every round key on top of the epoch hash
+func (n *Network) encode(index, tweakHash uint64, invert bool) (uint64, error) {
+	var epoch uint64
 	var epochStart uint64
 	var epochHash uint64
 	domainSize := n.maxValue + 1
 
 	if index > n.maxValue {
 		if n.epochs {
+			epoch = index / domainSize
 			epochStart = index - (index % domainSize)
-			epochHash = splitmix64(epochStart)
+			epochHash = n.epochHash(epoch)
 			index %= domainSize
 		} else {
 			return 0, fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, index, n.maxValue)
 		}
 	}
 
+	// A single value domain only has one permutation, every index maps to the start of its epoch
 	if n.maxValue == 0 {
-		return 0, nil
+		return epochStart, nil
+	}
+
+	value, err := n.permute(epoch, epochHash^tweakHash, tweakHash, index, invert, nil)
+	if err != nil {
+		return 0, withIndex(err, epochStart+index)
+	}
+
+	return value + epochStart, nil
+}
+
+// permute maps index inside an epoch, keyOffset is the epoch hash mixed with tweakHash.
+// Every path that maps within an epoch goes through here so options that change an epoch apply everywhere.
+func (n *Network) permute(epoch, keyOffset, tweakHash, index uint64, invert bool, cache *roundCache) (uint64, error) {
+	if n.boundarySpacing == 0 || epoch == 0 {
+		return n.cycleWalk(index, keyOffset, invert, cache)
+	}
+
+	return n.spacedPermute(epoch, keyOffset, tweakHash, index, invert, cache)
+}
+
+// cycleWalk runs the rounds until the result lands inside the domain, keyOffset is mixed into every round key
+// and cache is optional, when set it has to hold the round keys for keyOffset.
+// It can only fail when WithMaxWalks or WithConstantTime is used.
+func (n *Network) cycleWalk(index, keyOffset uint64, invert bool, cache *roundCache) (uint64, error) {
+	if n.exactShuffle {
+		return n.shuffled(index, keyOffset, invert), nil
+	}
+
+	if n.radices != nil {
+		return n.runBranches(index, keyOffset, invert), nil
 	}
 
 	a := index % n.leftRadix
 	b := index / n.leftRadix
 
-	for {
-		start := 0
-		adjust := 1
+	var result uint64
+	found := false
 
-		if invert {
-			start = n.rounds - 1
-			adjust = -1
+	for walks := uint64(1); ; walks++ {
+		a, b = n.runRounds(a, b, keyOffset, invert, cache, walks == 1)
+
+		value := a + b*n.leftRadix
+
+		// With constant time the rounds keep running on the value that was found until every walk is done
+		if value <= n.maxValue && !found {
+			result = value
+			found = true
 		}
 
-		for round := start; round >= 0 && round < n.rounds; round += adjust {
-			seed := n.seeds[round] ^ epochHash
+		if found && !n.constantTime {
+			return result, nil
+		}
 
-			if round%2 == 0 {
-				f := splitmix64(b^seed) % n.leftRadix
-				if invert {
-					a = (a + n.leftRadix - f) % n.leftRadix
-				} else {
-					a = (a + f) % n.leftRadix
-				}
-			} else {
-				f := splitmix64(a^seed) % n.rightRadix
-				if invert {
-					b = (b + n.rightRadix - f) % n.rightRadix
-				} else {
-					b = (b + f) % n.rightRadix
-				}
+		if walks == n.maxWalks {
+			if found {
+				return result, nil
 			}
+
+			return 0, &WalkLimitError{Index: index, Steps: walks}
 		}
+	}
+}
 
-		index = a + b*n.leftRadix
+func (n *Network) runRounds(a, b, keyOffset uint64, invert bool, cache *roundCache, firstWalk bool) (uint64, uint64) {
+	start := 0
+	adjust := 1
+
+	var keys []uint64
+	if cache != nil {
+		keys = cache.keys
+	}
+
+	if invert {
+		start = n.rounds - 1
+		adjust = -1
+	}
 
-		if index <= n.maxValue {
-			return index + epochStart, nil
+	round := start
+
+	// Consecutive indices share b so when the first round is keyed on b its value can be reused
+	if cache != nil && firstWalk && round%2 == 0 {
+		f := cache.lookup(n.roundFunc, keys[round], b, n.leftRadix)
+		if invert {
+			a = (a + n.leftRadix - f) % n.leftRadix
+		} else {
+			a = (a + f) % n.leftRadix
 		}
+		round += adjust
 	}
+
+	for ; round >= 0 && round < n.rounds; round += adjust {
+		var seed uint64
+		if keys != nil {
+			seed = keys[round]
+		} else {
+			seed = n.roundKey(round, keyOffset)
+		}
+
+		if round%2 == 0 {
+			f := n.roundFunc.Round(seed, b, n.leftRadix)
+			if invert {
+				a = (a + n.leftRadix - f) % n.leftRadix
+			} else {
+				a = (a + f) % n.leftRadix
+			}
+		} else {
+			f := n.roundFunc.Round(seed, a, n.rightRadix)
+			if invert {
+				b = (b + n.rightRadix - f) % n.rightRadix
+			} else {
+				b = (b + f) % n.rightRadix
+			}
+		}
+	}
+
+	return a, b
 }
 
 func findFactors(maxValue uint64) (uint64, uint64) {
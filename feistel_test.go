@@ -16,19 +16,23 @@ import (
 
 const (
 	maxTestRange       = 1000000
+	roundFuncTestRange = 100000
 	minRoundsForRandom = 7
 )
 
 type testSettings struct {
-	maxValue   uint64
-	rounds     uint8
-	startIndex uint64
-	endIndex   uint64
-	epochs     bool
+	maxValue     uint64
+	rounds       uint8
+	startIndex   uint64
+	endIndex     uint64
+	epochs       bool
+	roundFunc    RoundFunc
+	testRange    uint64
+	exactRadices bool
 }
 
 func (s testSettings) String() string {
-	return fmt.Sprintf("maxValue %d, range: %d -> %d, rounds: %d, epochs: %t", s.maxValue, s.startIndex, s.endIndex, s.rounds, s.epochs)
+	return fmt.Sprintf("maxValue %d, range: %d -> %d, rounds: %d, epochs: %t, roundFunc: %T, exactRadices: %t", s.maxValue, s.startIndex, s.endIndex, s.rounds, s.epochs, s.roundFunc, s.exactRadices)
 }
 
 type testResult struct {
@@ -60,18 +64,40 @@ var optionsToTest = []testSettings{
 	},
 }
 
+// The first round function is the default and runs over the whole matrix, every cached result holds its
+// whole range in memory so the others only run over roundFuncMaxValuesToTest and roundFuncTestRange
+var roundFuncsToTest = []RoundFunc{SplitMix64{}, SipHash24{}, XXH64{}, mustAES(testKey128)}
+var roundFuncMaxValuesToTest = []uint64{101, 1000}
+
+// WithExactRadices only changes domains that split into balanced radices, 1001 is 7 * 11 * 13
+var exactRadicesMaxValuesToTest = []uint64{1000}
+
 var cachedResults []*testResult
 
 func buildTestSettings() []*testSettings {
-	result := make([]*testSettings, len(maxValuesToTest)*len(optionsToTest)*2)
-	i := 0
+	var result []*testSettings
+
+	for i, roundFunc := range roundFuncsToTest {
+		if i == 0 {
+			result = appendTestSettings(result, roundFunc, maxValuesToTest, maxTestRange, false)
+		} else {
+			result = appendTestSettings(result, roundFunc, roundFuncMaxValuesToTest, roundFuncTestRange, false)
+		}
+	}
+
+	return appendTestSettings(result, roundFuncsToTest[0], exactRadicesMaxValuesToTest, maxTestRange, true)
+}
+
+func appendTestSettings(result []*testSettings, roundFunc RoundFunc, maxValues []uint64, testRange uint64, exactRadices bool) []*testSettings {
 	for _, epochs := range []bool{false, true} {
-		for _, maxValue := range maxValuesToTest {
+		for _, maxValue := range maxValues {
 			for _, settings := range optionsToTest {
 				settings.maxValue = maxValue
 				settings.epochs = epochs
-				result[i] = &settings
-				i++
+				settings.roundFunc = roundFunc
+				settings.testRange = testRange
+				settings.exactRadices = exactRadices
+				result = append(result, &settings)
 			}
 		}
 	}
@@ -86,13 +112,14 @@ func TestMain(m *testing.M) {
 		}
 	}
 
-	cachedResults = make([]*testResult, len(maxValuesToTest)*len(optionsToTest)*2)
+	allSettings := buildTestSettings()
+	cachedResults = make([]*testResult, len(allSettings))
 	resultChan := make(chan *testResult, 200)
 	var wg sync.WaitGroup
 
 	go func() {
 		semaphoreChannel := make(chan struct{}, runtime.NumCPU())
-		for _, settings := range buildTestSettings() {
+		for _, settings := range allSettings {
 			currentSettings := settings
 			semaphoreChannel <- struct{}{}
 			wg.Add(1)
@@ -119,10 +146,18 @@ func TestMain(m *testing.M) {
 func createNetwork(settings *testSettings, seed uint64) (*Network, error) {
 	var options []Option
 
+	if settings.roundFunc != nil {
+		options = append(options, WithRoundFunc(settings.roundFunc))
+	}
+
 	if settings.epochs {
 		options = append(options, WithEpochs())
 	}
 
+	if settings.exactRadices {
+		options = append(options, WithExactRadices())
+	}
+
 	if settings.endIndex == 0 {
 		settings.endIndex = settings.maxValue
 	}
@@ -138,10 +173,10 @@ func runTest(settings *testSettings) *testResult {
 	domainSize := settings.maxValue + 1
 	replaceNet := false
 
-	if settings.maxValue > maxTestRange {
-		settings.endIndex = maxTestRange
+	if settings.maxValue > settings.testRange {
+		settings.endIndex = settings.testRange
 	} else {
-		settings.endIndex = maxTestRange - (maxTestRange % domainSize)
+		settings.endIndex = settings.testRange - (settings.testRange % domainSize)
 		replaceNet = !settings.epochs
 	}
 
@@ -681,3 +716,36 @@ func TestCoverage(t *testing.T) {
 		})
 	}
 }
+
+func TestSingleValueDomainEpochs(t *testing.T) {
+	net, err := NewNetwork(0, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index := range uint64(10) {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != index {
+			t.Errorf("Expected %d to map to itself, got %d", index, mapped)
+		}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != index {
+			t.Errorf("Mapped %d to %d and inversion produced %d, %v", index, mapped, inverted, err)
+		}
+	}
+
+	dst := make([]uint64, 10)
+	if err := net.MapRange(dst, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, mapped := range dst {
+		if mapped != uint64(i) {
+			t.Errorf("Expected MapRange to map %d to itself, got %d", i, mapped)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultWalkLimit is the walk limit of a FilteredNetwork unless WithWalkLimit is used
+const DefaultWalkLimit = 1 << 20
+
+// ErrValueNotKept is returned when a FilteredNetwork is given a value its predicate rejects
+var ErrValueNotKept = errors.New("feistel: value is rejected by the predicate")
+
+// ErrWalkLimitExceeded is wrapped by WalkLimitError
+var ErrWalkLimitExceeded = errors.New("feistel: walk limit exceeded")
+
+// WalkLimitError is returned when cycle walking took more steps than allowed without finding a value to keep,
+// either in a FilteredNetwork or in a Network created WithMaxWalks. It matches ErrWalkLimitExceeded with errors.Is.
+type WalkLimitError struct {
+	// Index is the value that was being mapped
+	Index uint64
+	// Steps is the number of steps taken before giving up
+	Steps uint64
+}
+
+// Error implements error
+func (e *WalkLimitError) Error() string {
+	return fmt.Sprintf("%v, index: %d, steps: %d", ErrWalkLimitExceeded, e.Index, e.Steps)
+}
+
+// Unwrap returns ErrWalkLimitExceeded so errors.Is matches it
+func (e *WalkLimitError) Unwrap() error {
+	return ErrWalkLimitExceeded
+}
+
+// FilteredNetwork permutes only the values a predicate keeps. It cycle walks through the base Network
+// the same way Network walks back into [0, maxValue], every value that isn't kept is skipped over so the
+// kept values are permuted among themselves without ever being enumerated.
+// The expected walk is about the domain size divided by the number of kept values.
+type FilteredNetwork struct {
+	base      *Network
+	keep      func(uint64) bool
+	walkLimit uint64
+}
+
+var _ Mapper = (*FilteredNetwork)(nil)
+
+// FilterOption is an optional setting for a FilteredNetwork
+type FilterOption func(*FilteredNetwork)
+
+// WithWalkLimit sets how many steps of the base Network a single Map or InvertMap can take, 0 means no limit.
+// A walk always ends when it gets back to where it started, so without a limit it can take as many steps as
+// the cycle of the base permutation is long.
+func WithWalkLimit(limit uint64) FilterOption {
+	return func(f *FilteredNetwork) {
+		f.walkLimit = limit
+	}
+}
+
+// NewFilteredNetwork creates a FilteredNetwork that only maps values keep returns true for
+func NewFilteredNetwork(base *Network, keep func(uint64) bool, opts ...FilterOption) *FilteredNetwork {
+	f := &FilteredNetwork{
+		base:      base,
+		keep:      keep,
+		walkLimit: DefaultWalkLimit,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Network returns the base Network
+func (f *FilteredNetwork) Network() *Network {
+	return f.base
+}
+
+// Map takes a kept value and maps it to another kept value
+func (f *FilteredNetwork) Map(value uint64) (uint64, error) {
+	return f.walk(value, f.base.Map)
+}
+
+// InvertMap performs an inversion of Map
+func (f *FilteredNetwork) InvertMap(value uint64) (uint64, error) {
+	return f.walk(value, f.base.InvertMap)
+}
+
+func (f *FilteredNetwork) walk(value uint64, fn func(uint64) (uint64, error)) (uint64, error) {
+	if !f.keep(value) {
+		return 0, fmt.Errorf("%w, value: %d", ErrValueNotKept, value)
+	}
+
+	current := value
+	for steps := uint64(1); ; steps++ {
+		var err error
+		if current, err = fn(current); err != nil {
+			return 0, err
+		}
+
+		if f.keep(current) {
+			return current, nil
+		}
+
+		if steps == f.walkLimit {
+			return 0, &WalkLimitError{Index: value, Steps: steps}
+		}
+	}
+}
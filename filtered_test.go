@@ -0,0 +1,132 @@
+package feistel
+
+import (
+	"errors"
+	"testing"
+)
+
+// luhnValid reports whether the last decimal digit of value is the Luhn check digit of the rest
+func luhnValid(value uint64) bool {
+	sum := uint64(0)
+	for i := 0; value > 0; i++ {
+		digit := value % 10
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		value /= 10
+	}
+	return sum%10 == 0
+}
+
+func TestFilteredNetworkIsPermutationOfKept(t *testing.T) {
+	base, err := NewNetwork(99999, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net := NewFilteredNetwork(base, luhnValid)
+	seen := make(map[uint64]struct{})
+	kept := 0
+
+	for value := range uint64(100000) {
+		mapped, err := net.Map(value)
+		if !luhnValid(value) {
+			if !errors.Is(err, ErrValueNotKept) {
+				t.Fatalf("Expected ErrValueNotKept for %d, got %v", value, err)
+			}
+			continue
+		}
+		kept++
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !luhnValid(mapped) {
+			t.Fatalf("Mapped %d to %d which doesn't pass the check", value, mapped)
+		}
+
+		if _, ok := seen[mapped]; ok {
+			t.Fatalf("%d was mapped to twice", mapped)
+		}
+		seen[mapped] = struct{}{}
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != value {
+			t.Fatalf("Mapped %d to %d and inversion produced %d", value, mapped, inverted)
+		}
+	}
+
+	if len(seen) != kept {
+		t.Errorf("Expected %d outputs, got %d", kept, len(seen))
+	}
+}
+
+func TestFilteredNetworkWalkLimit(t *testing.T) {
+	base, err := NewNetwork(1<<20-1, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only two values are kept, so almost every walk is far longer than the limit
+	keep := func(value uint64) bool { return value == 3 || value == 1<<19 }
+	net := NewFilteredNetwork(base, keep, WithWalkLimit(100))
+
+	_, err = net.Map(3)
+
+	var walkErr *WalkLimitError
+	if !errors.As(err, &walkErr) || !errors.Is(err, ErrWalkLimitExceeded) {
+		t.Fatalf("Expected a WalkLimitError, got %v", err)
+	}
+
+	if walkErr.Index != 3 || walkErr.Steps != 100 {
+		t.Errorf("Expected the error to be for index 3 after 100 steps, got %d after %d", walkErr.Index, walkErr.Steps)
+	}
+
+	unlimited := NewFilteredNetwork(base, keep, WithWalkLimit(0))
+	mapped, err := unlimited.Map(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inverted, err := unlimited.InvertMap(mapped); err != nil || inverted != 3 {
+		t.Errorf("Mapped 3 to %d and inversion produced %d, %v", mapped, inverted, err)
+	}
+}
+
+func TestFilteredNetworkEpochs(t *testing.T) {
+	base, err := NewNetwork(999, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	even := func(value uint64) bool { return value%2 == 0 }
+	net := NewFilteredNetwork(base, even)
+
+	for _, value := range []uint64{0, 998, 1000, 5432} {
+		mapped, err := net.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped%2 != 0 || mapped/1000 != value/1000 {
+			t.Errorf("Mapped %d to %d which is odd or in a different epoch", value, mapped)
+		}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != value {
+			t.Errorf("Mapped %d to %d and inversion produced %d, %v", value, mapped, inverted, err)
+		}
+	}
+
+	if _, err := NewFilteredNetwork(base, even).Map(1); !errors.Is(err, ErrValueNotKept) {
+		t.Errorf("Expected ErrValueNotKept, got %v", err)
+	}
+}
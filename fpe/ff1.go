@@ -0,0 +1,176 @@
+package fpe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/mormehtar/feistel"
+)
+
+const ff1Rounds = 10
+
+// FF1 is the FF1 mode from NIST SP 800-38G, it accepts tweaks of any length
+type FF1 struct {
+	block  cipher.Block
+	radix  int
+	minLen int
+}
+
+var _ Cipher = (*FF1)(nil)
+
+// NewFF1 creates an FF1 cipher, key has to be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256
+func NewFF1(key []byte, radix int) (*FF1, error) {
+	if err := checkRadix(radix); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w, size: %d", feistel.ErrInvalidKeySize, len(key))
+	}
+
+	return &FF1{
+		block:  block,
+		radix:  radix,
+		minLen: minLength(radix),
+	}, nil
+}
+
+// Radix implements Cipher
+func (c *FF1) Radix() int {
+	return c.radix
+}
+
+// MinLength implements Cipher
+func (c *FF1) MinLength() int {
+	return c.minLen
+}
+
+// MaxLength implements Cipher
+func (c *FF1) MaxLength() int {
+	return math.MaxInt32
+}
+
+// Encrypt implements Cipher
+func (c *FF1) Encrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	return c.cipher(x, tweak, false)
+}
+
+// Decrypt implements Cipher
+func (c *FF1) Decrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	return c.cipher(x, tweak, true)
+}
+
+func (c *FF1) cipher(x []uint16, tweak []byte, decrypt bool) ([]uint16, error) {
+	if err := checkNumerals(x, c.radix, c.minLen, c.MaxLength()); err != nil {
+		return nil, err
+	}
+
+	n := len(x)
+	u := n / 2
+	v := n - u
+
+	radix := big.NewInt(int64(c.radix))
+	a := num(x[:u], radix)
+	b := num(x[u:], radix)
+
+	modU := new(big.Int).Exp(radix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(radix, big.NewInt(int64(v)), nil)
+
+	// byteLen is ceil(ceil(v * log2(radix)) / 8), ceil(log2(radix^v)) is the bit length of radix^v - 1
+	byteLen := (new(big.Int).Sub(modV, big.NewInt(1)).BitLen() + 7) / 8
+	d := 4*((byteLen+3)/4) + 4
+
+	prf := ff1PRF{
+		block: c.block,
+		p: [aes.BlockSize]byte{
+			1, 2, 1,
+			byte(c.radix >> 16), byte(c.radix >> 8), byte(c.radix),
+			ff1Rounds, byte(u),
+		},
+		tweak:   tweak,
+		byteLen: byteLen,
+		d:       d,
+	}
+	binary.BigEndian.PutUint32(prf.p[8:12], uint32(n))
+	binary.BigEndian.PutUint32(prf.p[12:16], uint32(len(tweak)))
+
+	for i := range ff1Rounds {
+		if decrypt {
+			round := ff1Rounds - 1 - i
+			modulus := modU
+			if round%2 == 1 {
+				modulus = modV
+			}
+
+			next := new(big.Int).Sub(b, prf.round(round, a))
+			next.Mod(next, modulus)
+			a, b = next, a
+		} else {
+			modulus := modU
+			if i%2 == 1 {
+				modulus = modV
+			}
+
+			next := new(big.Int).Add(a, prf.round(i, b))
+			next.Mod(next, modulus)
+			a, b = b, next
+		}
+	}
+
+	result := make([]uint16, n)
+	str(result[:u], a, radix)
+	str(result[u:], b, radix)
+
+	return result, nil
+}
+
+// ff1PRF computes the round function of FF1, steps 6.i to 6.iv of the encryption algorithm
+type ff1PRF struct {
+	block   cipher.Block
+	p       [aes.BlockSize]byte
+	tweak   []byte
+	byteLen int
+	d       int
+}
+
+func (f *ff1PRF) round(i int, half *big.Int) *big.Int {
+	padding := (-len(f.tweak) - f.byteLen - 1) % aes.BlockSize
+	if padding < 0 {
+		padding += aes.BlockSize
+	}
+
+	q := make([]byte, len(f.tweak)+padding+1+f.byteLen)
+	copy(q, f.tweak)
+	q[len(f.tweak)+padding] = byte(i)
+	half.FillBytes(q[len(q)-f.byteLen:])
+
+	// R is the CBC-MAC of P || Q with a zero IV
+	var r [aes.BlockSize]byte
+	f.block.Encrypt(r[:], f.p[:])
+	for start := 0; start < len(q); start += aes.BlockSize {
+		for j := range aes.BlockSize {
+			r[j] ^= q[start+j]
+		}
+		f.block.Encrypt(r[:], r[:])
+	}
+
+	// S is R followed by the encryptions of R xor j until there are at least d bytes
+	s := make([]byte, 0, f.d+aes.BlockSize)
+	s = append(s, r[:]...)
+	for j := uint64(1); len(s) < f.d; j++ {
+		var block [aes.BlockSize]byte
+		binary.BigEndian.PutUint64(block[8:], j)
+		for k := range aes.BlockSize {
+			block[k] ^= r[k]
+		}
+		f.block.Encrypt(block[:], block[:])
+		s = append(s, block[:]...)
+	}
+
+	return new(big.Int).SetBytes(s[:f.d])
+}
@@ -0,0 +1,148 @@
+package fpe
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+const (
+	nistKey128 = "2B7E151628AED2A6ABF7158809CF4F3C"
+	nistKey192 = "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F"
+	nistKey256 = "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94"
+)
+
+type nistSample struct {
+	name       string
+	key        string
+	alphabet   Alphabet
+	tweak      string
+	plaintext  string
+	ciphertext string
+}
+
+// The FF1 samples published by NIST for SP 800-38G
+var ff1Samples = []nistSample{
+	{"sample 1", nistKey128, Digits, "", "0123456789", "2433477484"},
+	{"sample 2", nistKey128, Digits, "39383736353433323130", "0123456789", "6124200773"},
+	{"sample 3", nistKey128, Base36, "3737373770717273373737", "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+	{"sample 4", nistKey192, Digits, "", "0123456789", "2830668132"},
+	{"sample 5", nistKey192, Digits, "39383736353433323130", "0123456789", "2496655549"},
+	{"sample 6", nistKey192, Base36, "3737373770717273373737", "0123456789abcdefghi", "xbj3kv35jrawxv32ysr"},
+	{"sample 7", nistKey256, Digits, "", "0123456789", "6657667009"},
+	{"sample 8", nistKey256, Digits, "39383736353433323130", "0123456789", "1001623463"},
+	{"sample 9", nistKey256, Base36, "3737373770717273373737", "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func testSamples(t *testing.T, samples []nistSample, newCipher func(key []byte, radix int) (Cipher, error)) {
+	for _, sample := range samples {
+		t.Run(sample.name, func(t *testing.T) {
+			c, err := newCipher(mustDecodeHex(t, sample.key), sample.alphabet.Radix())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tweak := mustDecodeHex(t, sample.tweak)
+
+			ciphertext, err := EncryptString(c, sample.alphabet, sample.plaintext, tweak)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ciphertext != sample.ciphertext {
+				t.Errorf("Expected %s to encrypt to %s but got %s", sample.plaintext, sample.ciphertext, ciphertext)
+			}
+
+			plaintext, err := DecryptString(c, sample.alphabet, sample.ciphertext, tweak)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if plaintext != sample.plaintext {
+				t.Errorf("Expected %s to decrypt to %s but got %s", sample.ciphertext, sample.plaintext, plaintext)
+			}
+		})
+	}
+}
+
+func TestFF1Samples(t *testing.T) {
+	testSamples(t, ff1Samples, func(key []byte, radix int) (Cipher, error) {
+		return NewFF1(key, radix)
+	})
+}
+
+func TestFF1RoundTripOddLengths(t *testing.T) {
+	c, err := NewFF1(mustDecodeHex(t, nistKey128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for length := c.MinLength(); length < 70; length++ {
+		x := make([]uint16, length)
+		for i := range x {
+			x[i] = uint16(i % 3 % 2)
+		}
+
+		encrypted, err := c.Encrypt(x, []byte("tweak"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decrypted, err := c.Decrypt(encrypted, []byte("tweak"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range x {
+			if decrypted[i] != x[i] {
+				t.Fatalf("Round trip failed for length %d, %v became %v", length, x, decrypted)
+			}
+		}
+	}
+}
+
+func TestFF1Errors(t *testing.T) {
+	if _, err := NewFF1(make([]byte, 10), 10); !errors.Is(err, feistel.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+
+	for _, radix := range []int{0, 1, 1<<16 + 1} {
+		if _, err := NewFF1(mustDecodeHex(t, nistKey128), radix); !errors.Is(err, ErrInvalidRadix) {
+			t.Errorf("Expected ErrInvalidRadix for radix %d, got %v", radix, err)
+		}
+	}
+
+	c, err := NewFF1(mustDecodeHex(t, nistKey128), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Encrypt([]uint16{1, 2, 3, 4, 5}, nil); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength for a domain smaller than a million, got %v", err)
+	}
+
+	if _, err := c.Encrypt([]uint16{1, 2, 3, 4, 5, 10}, nil); !errors.Is(err, ErrInvalidNumeral) {
+		t.Errorf("Expected ErrInvalidNumeral, got %v", err)
+	}
+
+	if _, err := EncryptString(c, Digits, "12345x", nil); !errors.Is(err, ErrInvalidNumeral) {
+		t.Errorf("Expected ErrInvalidNumeral for a character outside the alphabet, got %v", err)
+	}
+
+	if _, err := EncryptString(c, Base36, "123456", nil); !errors.Is(err, ErrInvalidRadix) {
+		t.Errorf("Expected ErrInvalidRadix for a mismatched alphabet, got %v", err)
+	}
+}
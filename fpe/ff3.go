@@ -0,0 +1,185 @@
+package fpe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/mormehtar/feistel"
+)
+
+const (
+	ff3Rounds = 8
+
+	// FF31TweakSize is the number of bytes in an FF3-1 tweak
+	FF31TweakSize = 7
+)
+
+// FF31 is the FF3-1 mode from NIST SP 800-38G Revision 1, tweaks are always 56 bits
+type FF31 struct {
+	block  cipher.Block
+	radix  int
+	minLen int
+	maxLen int
+}
+
+var _ Cipher = (*FF31)(nil)
+
+// NewFF31 creates an FF3-1 cipher, key has to be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256
+func NewFF31(key []byte, radix int) (*FF31, error) {
+	if err := checkRadix(radix); err != nil {
+		return nil, err
+	}
+
+	// FF3 runs AES with the bytes of the key in reverse order
+	block, err := aes.NewCipher(reverseBytes(key))
+	if err != nil {
+		return nil, fmt.Errorf("%w, size: %d", feistel.ErrInvalidKeySize, len(key))
+	}
+
+	return &FF31{
+		block:  block,
+		radix:  radix,
+		minLen: minLength(radix),
+		maxLen: 2 * maxHalfLength(radix),
+	}, nil
+}
+
+// Radix implements Cipher
+func (c *FF31) Radix() int {
+	return c.radix
+}
+
+// MinLength implements Cipher
+func (c *FF31) MinLength() int {
+	return c.minLen
+}
+
+// MaxLength implements Cipher
+func (c *FF31) MaxLength() int {
+	return c.maxLen
+}
+
+// Encrypt implements Cipher, tweak has to be FF31TweakSize bytes
+func (c *FF31) Encrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	left, right, err := splitFF31Tweak(tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.cipher(x, left, right, false)
+}
+
+// Decrypt implements Cipher, tweak has to be FF31TweakSize bytes
+func (c *FF31) Decrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	left, right, err := splitFF31Tweak(tweak)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.cipher(x, left, right, true)
+}
+
+// splitFF31Tweak builds the two 32 bit tweak halves of FF3 out of the 56 bit tweak of FF3-1,
+// the left half is the first 28 bits and the right half is the last 24 bits followed by the 4 bits in between
+func splitFF31Tweak(tweak []byte) (left, right [4]byte, err error) {
+	if len(tweak) != FF31TweakSize {
+		return left, right, fmt.Errorf("%w, FF3-1 tweaks are %d bytes, got %d", ErrInvalidTweak, FF31TweakSize, len(tweak))
+	}
+
+	left = [4]byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xf0}
+	right = [4]byte{tweak[4], tweak[5], tweak[6], tweak[3] << 4}
+
+	return left, right, nil
+}
+
+// cipher is the FF3 algorithm with the tweak already split into halves
+func (c *FF31) cipher(x []uint16, tweakLeft, tweakRight [4]byte, decrypt bool) ([]uint16, error) {
+	if err := checkNumerals(x, c.radix, c.minLen, c.maxLen); err != nil {
+		return nil, err
+	}
+
+	n := len(x)
+	u := (n + 1) / 2
+	v := n - u
+
+	radix := big.NewInt(int64(c.radix))
+
+	// The halves are read with the least significant numeral first
+	a := num(reverse(x[:u]), radix)
+	b := num(reverse(x[u:]), radix)
+
+	modU := new(big.Int).Exp(radix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(radix, big.NewInt(int64(v)), nil)
+
+	for i := range ff3Rounds {
+		round := i
+		if decrypt {
+			round = ff3Rounds - 1 - i
+		}
+
+		modulus := modU
+		tweak := tweakRight
+		if round%2 == 1 {
+			modulus = modV
+			tweak = tweakLeft
+		}
+
+		if decrypt {
+			next := new(big.Int).Sub(b, c.round(round, tweak, a))
+			next.Mod(next, modulus)
+			a, b = next, a
+		} else {
+			next := new(big.Int).Add(a, c.round(i, tweak, b))
+			next.Mod(next, modulus)
+			a, b = b, next
+		}
+	}
+
+	result := make([]uint16, n)
+	str(result[:u], a, radix)
+	str(result[u:], b, radix)
+	slices.Reverse(result[:u])
+	slices.Reverse(result[u:])
+
+	return result, nil
+}
+
+// round computes y for round i, steps 4.ii to 4.iv of the encryption algorithm
+func (c *FF31) round(i int, tweak [4]byte, half *big.Int) *big.Int {
+	var p [aes.BlockSize]byte
+
+	binary.BigEndian.PutUint32(p[:4], binary.BigEndian.Uint32(tweak[:])^uint32(i))
+	half.FillBytes(p[4:])
+
+	slices.Reverse(p[:])
+	c.block.Encrypt(p[:], p[:])
+	slices.Reverse(p[:])
+
+	return new(big.Int).SetBytes(p[:])
+}
+
+// maxHalfLength returns the largest length for which radix^length fits in 96 bits
+func maxHalfLength(radix int) int {
+	limit := new(big.Int).Lsh(big.NewInt(1), 96)
+	size := big.NewInt(1)
+	r := big.NewInt(int64(radix))
+
+	length := 0
+	for {
+		size.Mul(size, r)
+		if size.Cmp(limit) > 0 {
+			return length
+		}
+		length++
+	}
+}
+
+func reverseBytes(b []byte) []byte {
+	result := slices.Clone(b)
+	slices.Reverse(result)
+	return result
+}
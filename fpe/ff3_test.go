@@ -0,0 +1,80 @@
+package fpe
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// The FF3-1 sample from the NIST ACVP test vectors
+var ff31Samples = []nistSample{
+	{"sample 1", "2DE79D232DF5585D68CE47882AE256D6", Digits, "CBD09280979564", "3992520240", "8901801106"},
+}
+
+// The samples NIST published for the original FF3, they use a 64 bit tweak so they exercise
+// the FF3 rounds underneath FF3-1 directly
+var ff3Samples = []nistSample{
+	{"sample 1", "EF4359D8D580AA4F7F036D6F04FC6A94", Digits, "D8E7920AFA330A73", "890121234567890000", "750918814058654607"},
+	{"sample 2", "EF4359D8D580AA4F7F036D6F04FC6A94", Digits, "9A768A92F60E12D8", "890121234567890000", "018989839189395384"},
+	{"sample 3", "EF4359D8D580AA4F7F036D6F04FC6A94", Digits, "D8E7920AFA330A73", "89012123456789000000789000000", "48598367162252569629397416226"},
+	{"sample 4", "EF4359D8D580AA4F7F036D6F04FC6A94", Digits, "0000000000000000", "89012123456789000000789000000", "34695224821734535122613701434"},
+	{"sample 5", "EF4359D8D580AA4F7F036D6F04FC6A94", Base36[:26], "9A768A92F60E12D8", "0123456789abcdefghi", "g2pk40i992fn20cjakb"},
+}
+
+// ff3 adapts FF31 to the 64 bit tweaks of the original FF3
+type ff3 struct {
+	*FF31
+}
+
+func (c ff3) Encrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	return c.cipher(x, [4]byte(tweak[:4]), [4]byte(tweak[4:]), false)
+}
+
+func (c ff3) Decrypt(x []uint16, tweak []byte) ([]uint16, error) {
+	return c.cipher(x, [4]byte(tweak[:4]), [4]byte(tweak[4:]), true)
+}
+
+func TestFF31Samples(t *testing.T) {
+	testSamples(t, ff31Samples, func(key []byte, radix int) (Cipher, error) {
+		return NewFF31(key, radix)
+	})
+}
+
+func TestFF3Samples(t *testing.T) {
+	testSamples(t, ff3Samples, func(key []byte, radix int) (Cipher, error) {
+		c, err := NewFF31(key, radix)
+		return ff3{c}, err
+	})
+}
+
+func TestFF31TweakSplit(t *testing.T) {
+	left, right, err := splitFF31Tweak([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(left[:]) != "12345670" || hex.EncodeToString(right[:]) != "9abcde80" {
+		t.Errorf("Unexpected tweak halves %x and %x", left, right)
+	}
+}
+
+func TestFF31Errors(t *testing.T) {
+	c, err := NewFF31(mustDecodeHex(t, nistKey128), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MaxLength() != 56 {
+		t.Errorf("Expected max length of 56 for radix 10, got %d", c.MaxLength())
+	}
+
+	for _, size := range []int{0, 6, 8} {
+		if _, err := c.Encrypt([]uint16{1, 2, 3, 4, 5, 6}, make([]byte, size)); !errors.Is(err, ErrInvalidTweak) {
+			t.Errorf("Expected ErrInvalidTweak for a %d byte tweak, got %v", size, err)
+		}
+	}
+
+	if _, err := c.Encrypt(make([]uint16, 57), make([]byte, FF31TweakSize)); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+}
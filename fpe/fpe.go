@@ -0,0 +1,191 @@
+// Package fpe implements the NIST SP 800-38G format-preserving encryption modes FF1 and FF3-1
+// They encrypt numeral strings of any radix between 2 and 65536 to numeral strings of the same length and radix
+// Use NewNetwork to run them over an integer range anywhere a feistel.Mapper is accepted
+package fpe
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+const (
+	minRadix = 2
+	maxRadix = 1 << 16
+
+	// minDomainSize is the smallest radix^length allowed by SP 800-38G
+	minDomainSize = 1_000_000
+)
+
+// ErrInvalidRadix is returned when the radix is outside of [2, 65536]
+var ErrInvalidRadix = errors.New("fpe: radix must be between 2 and 65536")
+
+// ErrInvalidLength is returned when the numeral string is too short or too long for the cipher
+var ErrInvalidLength = errors.New("fpe: invalid numeral string length")
+
+// ErrInvalidNumeral is returned when a numeral is not smaller than the radix or a character isn't in the alphabet
+var ErrInvalidNumeral = errors.New("fpe: invalid numeral")
+
+// ErrInvalidTweak is returned when the tweak doesn't have the length the cipher requires
+var ErrInvalidTweak = errors.New("fpe: invalid tweak")
+
+// Cipher is a format-preserving cipher over numeral strings of a fixed radix
+type Cipher interface {
+	// Radix is the number of possible values of each numeral
+	Radix() int
+	// MinLength is the shortest numeral string the cipher accepts
+	MinLength() int
+	// MaxLength is the longest numeral string the cipher accepts
+	MaxLength() int
+	// Encrypt returns a new numeral string of the same length as x
+	Encrypt(x []uint16, tweak []byte) ([]uint16, error)
+	// Decrypt performs an inversion of Encrypt
+	Decrypt(x []uint16, tweak []byte) ([]uint16, error)
+}
+
+// Alphabet maps numerals to characters, numeral i is the i-th character so the radix is the number of characters
+type Alphabet string
+
+const (
+	// Digits is the alphabet of radix 10
+	Digits Alphabet = "0123456789"
+	// Base36 is the alphabet of radix 36 used by the NIST samples
+	Base36 Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+// Radix returns the number of characters in the alphabet
+func (a Alphabet) Radix() int {
+	return utf8.RuneCountInString(string(a))
+}
+
+// Numerals converts a string of characters from the alphabet into numerals
+func (a Alphabet) Numerals(s string) ([]uint16, error) {
+	lookup := make(map[rune]uint16, len(a))
+	i := 0
+	for _, r := range a {
+		lookup[r] = uint16(i)
+		i++
+	}
+
+	result := make([]uint16, 0, len(s))
+	for _, r := range s {
+		numeral, ok := lookup[r]
+		if !ok {
+			return nil, fmt.Errorf("%w, character %q is not in the alphabet", ErrInvalidNumeral, r)
+		}
+
+		result = append(result, numeral)
+	}
+
+	return result, nil
+}
+
+// String converts numerals back into a string of characters from the alphabet
+func (a Alphabet) String(numerals []uint16) (string, error) {
+	runes := []rune(string(a))
+	result := make([]rune, len(numerals))
+
+	for i, numeral := range numerals {
+		if int(numeral) >= len(runes) {
+			return "", fmt.Errorf("%w, numeral: %d, radix: %d", ErrInvalidNumeral, numeral, len(runes))
+		}
+
+		result[i] = runes[numeral]
+	}
+
+	return string(result), nil
+}
+
+// EncryptString encrypts a string written in alphabet, the radix of alphabet has to match the cipher
+func EncryptString(c Cipher, alphabet Alphabet, s string, tweak []byte) (string, error) {
+	return cipherString(c.Encrypt, c.Radix(), alphabet, s, tweak)
+}
+
+// DecryptString performs an inversion of EncryptString
+func DecryptString(c Cipher, alphabet Alphabet, s string, tweak []byte) (string, error) {
+	return cipherString(c.Decrypt, c.Radix(), alphabet, s, tweak)
+}
+
+func cipherString(fn func([]uint16, []byte) ([]uint16, error), radix int, alphabet Alphabet, s string, tweak []byte) (string, error) {
+	if alphabet.Radix() != radix {
+		return "", fmt.Errorf("%w, alphabet has %d characters but the cipher radix is %d", ErrInvalidRadix, alphabet.Radix(), radix)
+	}
+
+	numerals, err := alphabet.Numerals(s)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := fn(numerals, tweak)
+	if err != nil {
+		return "", err
+	}
+
+	return alphabet.String(result)
+}
+
+func checkRadix(radix int) error {
+	if radix < minRadix || radix > maxRadix {
+		return fmt.Errorf("%w, radix: %d", ErrInvalidRadix, radix)
+	}
+
+	return nil
+}
+
+// minLength returns the smallest length for which radix^length is at least one million
+func minLength(radix int) int {
+	length := 0
+	for size := 1; size < minDomainSize; size *= radix {
+		length++
+	}
+
+	return max(length, 2)
+}
+
+func checkNumerals(x []uint16, radix, minLen, maxLen int) error {
+	if len(x) < minLen || len(x) > maxLen {
+		return fmt.Errorf("%w, length: %d, min: %d, max: %d", ErrInvalidLength, len(x), minLen, maxLen)
+	}
+
+	for _, numeral := range x {
+		if int(numeral) >= radix {
+			return fmt.Errorf("%w, numeral: %d, radix: %d", ErrInvalidNumeral, numeral, radix)
+		}
+	}
+
+	return nil
+}
+
+// num interprets x as a number in the given radix with the most significant numeral first
+func num(x []uint16, radix *big.Int) *big.Int {
+	result := new(big.Int)
+	digit := new(big.Int)
+
+	for _, numeral := range x {
+		result.Mul(result, radix)
+		result.Add(result, digit.SetUint64(uint64(numeral)))
+	}
+
+	return result
+}
+
+// str writes x into dst as a numeral string in the given radix with the most significant numeral first
+func str(dst []uint16, x, radix *big.Int) {
+	value := new(big.Int).Set(x)
+	digit := new(big.Int)
+
+	for i := len(dst) - 1; i >= 0; i-- {
+		value.QuoRem(value, radix, digit)
+		dst[i] = uint16(digit.Uint64())
+	}
+}
+
+func reverse(x []uint16) []uint16 {
+	result := make([]uint16, len(x))
+	for i, numeral := range x {
+		result[len(x)-1-i] = numeral
+	}
+
+	return result
+}
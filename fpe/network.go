@@ -0,0 +1,145 @@
+package fpe
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/mormehtar/feistel"
+)
+
+// MaxWalkFactor is how many times larger than max value + 1 the numeral domain can be, Map and InvertMap
+// run the cipher that many times on average so NewNetwork refuses anything above it
+const MaxWalkFactor = 1 << 10
+
+// ErrDomainTooLarge is returned when max value needs more numerals than the cipher accepts
+var ErrDomainTooLarge = errors.New("fpe: domain is too large for the cipher")
+
+// ErrDomainTooSmall is returned when the numeral domain is more than MaxWalkFactor times larger than max value + 1
+var ErrDomainTooSmall = errors.New("fpe: domain is too small for the cipher")
+
+// Network runs a Cipher over the integers from 0 to max value so it can be used anywhere a feistel.Mapper is.
+// Indices are written as the shortest numeral string the cipher accepts that can hold max value and
+// results above max value are encrypted again (cycle walking) like feistel.Network does.
+// SP 800-38G requires at least a million possible numeral strings so small domains would walk a lot,
+// NewNetwork refuses domains that would walk more than MaxWalkFactor times on average, which with a radix of 2
+// means max value has to be at least 1023. A radix of 2 keeps the numeral domain within a factor of 2 of max value
+// once it's above a million.
+type Network struct {
+	cipher   Cipher
+	tweak    []byte
+	maxValue uint64
+	length   int
+	radix    uint64
+}
+
+var _ feistel.Mapper = (*Network)(nil)
+
+// NewNetwork creates a Network permuting the range from 0 to maxValue with cipher, tweak is passed to every call
+// of Encrypt and Decrypt so different tweaks give different permutations. A max value of 0 is always allowed
+// since its only permutation doesn't need the cipher.
+func NewNetwork(cipher Cipher, maxValue uint64, tweak []byte) (*Network, error) {
+	radix := uint64(cipher.Radix())
+	length := 0
+
+	// Count the numerals needed to write maxValue
+	for value := maxValue; value > 0; value /= radix {
+		length++
+	}
+
+	length = max(length, cipher.MinLength())
+
+	if length > cipher.MaxLength() {
+		return nil, fmt.Errorf("%w, maxValue: %d needs %d numerals, max: %d", ErrDomainTooLarge, maxValue, length, cipher.MaxLength())
+	}
+
+	if maxValue > 0 && !withinWalkFactor(radix, length, maxValue) {
+		return nil, fmt.Errorf("%w, %d numerals of radix %d are more than %d times maxValue: %d", ErrDomainTooSmall, length, radix, MaxWalkFactor, maxValue)
+	}
+
+	return &Network{
+		cipher:   cipher,
+		tweak:    tweak,
+		maxValue: maxValue,
+		length:   length,
+		radix:    radix,
+	}, nil
+}
+
+// Map takes an index in a sequence and maps it to another index in the same sequence
+func (n *Network) Map(index uint64) (uint64, error) {
+	return n.walk(index, n.cipher.Encrypt)
+}
+
+// InvertMap performs an inversion of Map
+func (n *Network) InvertMap(index uint64) (uint64, error) {
+	return n.walk(index, n.cipher.Decrypt)
+}
+
+func (n *Network) walk(index uint64, fn func([]uint16, []byte) ([]uint16, error)) (uint64, error) {
+	if index > n.maxValue {
+		return 0, fmt.Errorf("%w, index: %d, maxSize: %d", feistel.ErrIndexGreatThanMaxValue, index, n.maxValue)
+	}
+
+	// A single value domain only has one permutation
+	if n.maxValue == 0 {
+		return 0, nil
+	}
+
+	numerals := make([]uint16, n.length)
+	value := index
+
+	for i := len(numerals) - 1; i >= 0; i-- {
+		numerals[i] = uint16(value % n.radix)
+		value /= n.radix
+	}
+
+	for {
+		var err error
+		numerals, err = fn(numerals, n.tweak)
+		if err != nil {
+			return 0, err
+		}
+
+		if value, ok := n.toUint64(numerals); ok && value <= n.maxValue {
+			return value, nil
+		}
+	}
+}
+
+// withinWalkFactor reports whether radix^length is at most MaxWalkFactor times maxValue + 1
+func withinWalkFactor(radix uint64, length int, maxValue uint64) bool {
+	hi, limit := bits.Mul64(maxValue+1, MaxWalkFactor)
+	if hi != 0 || maxValue == ^uint64(0) {
+		return true
+	}
+
+	size := uint64(1)
+	for range length {
+		hi, size = bits.Mul64(size, radix)
+		if hi != 0 || size > limit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toUint64 returns false if the numerals don't fit in a uint64
+func (n *Network) toUint64(numerals []uint16) (uint64, bool) {
+	var value uint64
+
+	for _, numeral := range numerals {
+		hi, lo := bits.Mul64(value, n.radix)
+		if hi != 0 {
+			return 0, false
+		}
+
+		value, hi = bits.Add64(lo, uint64(numeral), 0)
+		if hi != 0 {
+			return 0, false
+		}
+	}
+
+	return value, true
+}
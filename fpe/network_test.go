@@ -0,0 +1,189 @@
+package fpe
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+func TestNetworkIsPermutation(t *testing.T) {
+	ff1, err := NewFF1(mustDecodeHex(t, nistKey128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ff31, err := NewFF31(mustDecodeHex(t, nistKey128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cipher   Cipher
+		tweak    []byte
+		maxValue uint64
+	}{
+		{ff1, []byte("tweak"), 1<<20 + 1000},
+		{ff31, []byte("tweak56"), 1<<20 + 1000},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%T", test.cipher), func(t *testing.T) {
+			net, err := NewNetwork(test.cipher, test.maxValue, test.tweak)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var mapper feistel.Mapper = net
+			seen := make(map[uint64]struct{}, 2000)
+
+			// Walk the tail of the range where cycle walking happens
+			for index := test.maxValue - 2000; index <= test.maxValue; index++ {
+				mapped, err := mapper.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mapped > test.maxValue {
+					t.Fatalf("Mapped %d to %d which is above max value", index, mapped)
+				}
+
+				if _, ok := seen[mapped]; ok {
+					t.Fatalf("%d was mapped twice", mapped)
+				}
+				seen[mapped] = struct{}{}
+
+				inverted, err := mapper.InvertMap(mapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if inverted != index {
+					t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkFullUint64(t *testing.T) {
+	c, err := NewFF1(mustDecodeHex(t, nistKey256), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net, err := NewNetwork(c, ^uint64(0), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []uint64{0, 1, ^uint64(0)} {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != index {
+			t.Errorf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+		}
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	c, err := NewFF31(mustDecodeHex(t, nistKey128), 1<<16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewNetwork(c, ^uint64(0), make([]byte, FF31TweakSize)); err != nil {
+		t.Errorf("Expected 4 numerals of radix 65536 to fit, got %v", err)
+	}
+
+	small, err := NewFF31(mustDecodeHex(t, nistKey128), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net, err := NewNetwork(small, 1<<20, make([]byte, FF31TweakSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.Map(1<<20 + 1); !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+
+	badTweak, err := NewNetwork(small, 1<<20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := badTweak.Map(0); !errors.Is(err, ErrInvalidTweak) {
+		t.Errorf("Expected ErrInvalidTweak, got %v", err)
+	}
+}
+
+// shortCipher limits the length accepted by FF1 to check NewNetwork refuses domains that don't fit
+type shortCipher struct {
+	*FF1
+}
+
+func (shortCipher) MaxLength() int {
+	return 8
+}
+
+func TestNetworkDomainTooLarge(t *testing.T) {
+	c, err := NewFF1(mustDecodeHex(t, nistKey128), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewNetwork(shortCipher{c}, 99_999_999, nil); err != nil {
+		t.Errorf("Expected 8 digits to fit, got %v", err)
+	}
+
+	if _, err := NewNetwork(shortCipher{c}, 100_000_000, nil); !errors.Is(err, ErrDomainTooLarge) {
+		t.Errorf("Expected ErrDomainTooLarge, got %v", err)
+	}
+}
+
+func TestNetworkDomainTooSmall(t *testing.T) {
+	c, err := NewFF1(mustDecodeHex(t, nistKey128), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// FF1 needs 6 decimal numerals so a million strings are at most 1024 times max value + 1 from 976 upwards
+	if _, err := NewNetwork(c, 976, nil); err != nil {
+		t.Errorf("Expected 976 to be within the walk factor, got %v", err)
+	}
+
+	for _, maxValue := range []uint64{1, 99, 975} {
+		if _, err := NewNetwork(c, maxValue, nil); !errors.Is(err, ErrDomainTooSmall) {
+			t.Errorf("Expected ErrDomainTooSmall for max value %d, got %v", maxValue, err)
+		}
+	}
+
+	net, err := NewNetwork(c, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mapped, err := net.Map(0); err != nil || mapped != 0 {
+		t.Errorf("Expected 0 to map to itself, got %d, %v", mapped, err)
+	}
+
+	if inverted, err := net.InvertMap(0); err != nil || inverted != 0 {
+		t.Errorf("Expected 0 to invert to itself, got %d, %v", inverted, err)
+	}
+
+	if _, err := net.Map(1); !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// ErrInvalidGrid is returned when a grid has no dimensions, a dimension of size 0 or more cells than fit in a uint64
+var ErrInvalidGrid = errors.New("feistel: invalid grid")
+
+// ErrInvalidCoordinates is returned when the number of coordinates doesn't match the number of dimensions
+var ErrInvalidCoordinates = errors.New("feistel: invalid number of coordinates")
+
+// GridNetwork permutes the cells of a grid with any number of dimensions. Coordinates are read as digits of a
+// mixed radix number in row-major order, the last dimension changes fastest, and mapped by a Network over all the cells.
+type GridNetwork struct {
+	network *Network
+	sizes   []uint64
+}
+
+// NewGridNetwork creates a GridNetwork with the given size in each dimension,
+// seed, rounds and opts are passed to NewNetwork, there's nothing past the last cell so epochs have no effect.
+func NewGridNetwork(sizes []uint64, seed uint64, rounds uint8, opts ...Option) (*GridNetwork, error) {
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("%w, there has to be at least one dimension", ErrInvalidGrid)
+	}
+
+	cells := uint64(1)
+	for i, size := range sizes {
+		if size == 0 {
+			return nil, fmt.Errorf("%w, dimension %d has size 0", ErrInvalidGrid, i)
+		}
+
+		hi, lo := bits.Mul64(cells, size)
+		if hi != 0 {
+			return nil, fmt.Errorf("%w, the number of cells doesn't fit in a uint64", ErrInvalidGrid)
+		}
+		cells = lo
+	}
+
+	network, err := NewNetwork(cells-1, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridNetwork{network: network, sizes: slices.Clone(sizes)}, nil
+}
+
+// Network returns the underlying Network over the flattened cells
+func (g *GridNetwork) Network() *Network {
+	return g.network
+}
+
+// Sizes returns the size of each dimension
+func (g *GridNetwork) Sizes() []uint64 {
+	return slices.Clone(g.sizes)
+}
+
+// Map takes the coordinates of a cell and maps them to the coordinates of another cell
+func (g *GridNetwork) Map(coords []uint64) ([]uint64, error) {
+	return g.encode(coords, g.network.Map)
+}
+
+// InvertMap performs an inversion of Map
+func (g *GridNetwork) InvertMap(coords []uint64) ([]uint64, error) {
+	return g.encode(coords, g.network.InvertMap)
+}
+
+// Cells returns an iterator over every cell exactly once in the order of the permutation, paired with its position.
+// The coordinates are reused between iterations so clone them if you keep them.
+func (g *GridNetwork) Cells() iter.Seq2[uint64, []uint64] {
+	return func(yield func(uint64, []uint64) bool) {
+		coords := make([]uint64, len(g.sizes))
+
+		for index, value := range g.network.All() {
+			g.unflatten(value, coords)
+			if !yield(index, coords) {
+				return
+			}
+		}
+	}
+}
+
+func (g *GridNetwork) encode(coords []uint64, fn func(uint64) (uint64, error)) ([]uint64, error) {
+	value, err := g.flatten(coords)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, err := fn(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]uint64, len(g.sizes))
+	g.unflatten(mapped, result)
+
+	return result, nil
+}
+
+func (g *GridNetwork) flatten(coords []uint64) (uint64, error) {
+	if len(coords) != len(g.sizes) {
+		return 0, fmt.Errorf("%w, got %d, expected %d", ErrInvalidCoordinates, len(coords), len(g.sizes))
+	}
+
+	var value uint64
+	for i, coord := range coords {
+		if coord >= g.sizes[i] {
+			return 0, fmt.Errorf("%w, index: %d, maxSize: %d, dimension: %d", ErrIndexGreatThanMaxValue, coord, g.sizes[i]-1, i)
+		}
+		value = value*g.sizes[i] + coord
+	}
+
+	return value, nil
+}
+
+func (g *GridNetwork) unflatten(value uint64, coords []uint64) {
+	for i := len(g.sizes) - 1; i >= 0; i-- {
+		coords[i] = value % g.sizes[i]
+		value /= g.sizes[i]
+	}
+}
+
+// RectNetwork permutes the points of an image.Rectangle, for example to reveal an image pixel by pixel in a
+// random looking order like a fizzlefade
+type RectNetwork struct {
+	grid *GridNetwork
+	rect image.Rectangle
+}
+
+// NewRectNetwork creates a RectNetwork for the points of r, seed, rounds and opts are passed to NewNetwork
+func NewRectNetwork(r image.Rectangle, seed uint64, rounds uint8, opts ...Option) (*RectNetwork, error) {
+	r = r.Canon()
+	if r.Empty() {
+		return nil, fmt.Errorf("%w, %v is empty", ErrInvalidGrid, r)
+	}
+
+	grid, err := NewGridNetwork([]uint64{uint64(r.Dy()), uint64(r.Dx())}, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RectNetwork{grid: grid, rect: r}, nil
+}
+
+// Grid returns the underlying GridNetwork, its first dimension is y and its second is x relative to the corner of the rectangle
+func (r *RectNetwork) Grid() *GridNetwork {
+	return r.grid
+}
+
+// Map takes a point in the rectangle and maps it to another point in the rectangle
+func (r *RectNetwork) Map(p image.Point) (image.Point, error) {
+	return r.encode(p, r.grid.Map)
+}
+
+// InvertMap performs an inversion of Map
+func (r *RectNetwork) InvertMap(p image.Point) (image.Point, error) {
+	return r.encode(p, r.grid.InvertMap)
+}
+
+// Points returns an iterator over every point of the rectangle exactly once in the order of the permutation
+func (r *RectNetwork) Points() iter.Seq[image.Point] {
+	return func(yield func(image.Point) bool) {
+		for _, coords := range r.grid.Cells() {
+			if !yield(r.toPoint(coords)) {
+				return
+			}
+		}
+	}
+}
+
+func (r *RectNetwork) encode(p image.Point, fn func([]uint64) ([]uint64, error)) (image.Point, error) {
+	if !p.In(r.rect) {
+		return image.Point{}, fmt.Errorf("%w, %v is outside of %v", ErrIndexGreatThanMaxValue, p, r.rect)
+	}
+
+	mapped, err := fn([]uint64{uint64(p.Y - r.rect.Min.Y), uint64(p.X - r.rect.Min.X)})
+	if err != nil {
+		return image.Point{}, err
+	}
+
+	return r.toPoint(mapped), nil
+}
+
+func (r *RectNetwork) toPoint(coords []uint64) image.Point {
+	return image.Pt(r.rect.Min.X+int(coords[1]), r.rect.Min.Y+int(coords[0]))
+}
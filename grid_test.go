@@ -0,0 +1,144 @@
+package feistel
+
+import (
+	"errors"
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestGridNetworkIsPermutation(t *testing.T) {
+	sizes := []uint64{7, 5, 3}
+	net, err := NewGridNetwork(sizes, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[[3]uint64]struct{})
+	for x := range sizes[0] {
+		for y := range sizes[1] {
+			for z := range sizes[2] {
+				coords := []uint64{x, y, z}
+
+				mapped, err := net.Map(coords)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for i, coord := range mapped {
+					if coord >= sizes[i] {
+						t.Fatalf("Mapped %v to %v which is outside of the grid", coords, mapped)
+					}
+				}
+
+				key := [3]uint64(mapped)
+				if _, ok := seen[key]; ok {
+					t.Fatalf("%v was mapped to twice", mapped)
+				}
+				seen[key] = struct{}{}
+
+				inverted, err := net.InvertMap(mapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !slices.Equal(inverted, coords) {
+					t.Fatalf("Mapped %v to %v and inversion produced %v", coords, mapped, inverted)
+				}
+			}
+		}
+	}
+
+	if len(seen) != 105 {
+		t.Errorf("Expected 105 cells, got %d", len(seen))
+	}
+}
+
+func TestGridNetworkCells(t *testing.T) {
+	net, err := NewGridNetwork([]uint64{4, 6}, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[[2]uint64]struct{})
+	for index, cell := range net.Cells() {
+		seen[[2]uint64(cell)] = struct{}{}
+
+		// The cell at a position is the cell the flattened position maps to
+		start := []uint64{index / 6, index % 6}
+		mapped, err := net.Map(start)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(mapped, cell) {
+			t.Fatalf("Expected position %d to yield %v, got %v", index, mapped, cell)
+		}
+	}
+
+	if len(seen) != 24 {
+		t.Errorf("Expected every one of the 24 cells, got %d", len(seen))
+	}
+}
+
+func TestRectNetwork(t *testing.T) {
+	rect := image.Rect(-3, 10, 17, 22)
+	net, err := NewRectNetwork(rect, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[image.Point]struct{})
+	for p := range net.Points() {
+		if !p.In(rect) {
+			t.Fatalf("Point %v is outside of %v", p, rect)
+		}
+
+		if _, ok := seen[p]; ok {
+			t.Fatalf("Point %v was yielded twice", p)
+		}
+		seen[p] = struct{}{}
+
+		mapped, err := net.Map(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != p {
+			t.Fatalf("Mapped %v to %v and inversion produced %v, %v", p, mapped, inverted, err)
+		}
+	}
+
+	if len(seen) != rect.Dx()*rect.Dy() {
+		t.Errorf("Expected %d points, got %d", rect.Dx()*rect.Dy(), len(seen))
+	}
+
+	if _, err := net.Map(image.Pt(17, 10)); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue outside of the rectangle, got %v", err)
+	}
+}
+
+func TestGridNetworkErrors(t *testing.T) {
+	for _, sizes := range [][]uint64{nil, {3, 0}, {1 << 32, 1 << 32}} {
+		if _, err := NewGridNetwork(sizes, 1, 8); !errors.Is(err, ErrInvalidGrid) {
+			t.Errorf("Expected ErrInvalidGrid for %v, got %v", sizes, err)
+		}
+	}
+
+	if _, err := NewRectNetwork(image.Rect(0, 0, 0, 5), 1, 8); !errors.Is(err, ErrInvalidGrid) {
+		t.Errorf("Expected ErrInvalidGrid for an empty rectangle, got %v", err)
+	}
+
+	net, err := NewGridNetwork([]uint64{3, 3}, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.Map([]uint64{1}); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates, got %v", err)
+	}
+
+	if _, err := net.Map([]uint64{1, 3}); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package ids
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/mormehtar/feistel"
+)
+
+// ErrForgedID is returned by Decode when a string decodes to an ID that was never issued
+var ErrForgedID = errors.New("ids: ID was never issued")
+
+// ErrInvalidFalseAcceptRate is returned when the false accept probability is outside of (0, 1]
+// or too small for the sparse space to fit in a uint64
+var ErrInvalidFalseAcceptRate = errors.New("ids: invalid false accept rate")
+
+// WithMaxID makes the Codec reject IDs above maxID, Decode returns ErrForgedID for any string that
+// decodes to a larger value. Together with a network from SparseMaxValue this authenticates IDs.
+func WithMaxID(maxID uint64) Option {
+	return func(c *Codec) {
+		c.maxID = maxID
+	}
+}
+
+// SparseMaxValue returns the max value of a network that embeds [0, maxID] sparsely enough that a
+// guessed value decodes to a valid ID with a probability of at most falseAccept
+func SparseMaxValue(maxID uint64, falseAccept float64) (uint64, error) {
+	if !(falseAccept > 0 && falseAccept <= 1) {
+		return 0, fmt.Errorf("%w, falseAccept: %g", ErrInvalidFalseAcceptRate, falseAccept)
+	}
+
+	factor := math.Ceil(1 / falseAccept)
+	if factor >= 1<<64 {
+		return 0, fmt.Errorf("%w, falseAccept: %g", ErrInvalidFalseAcceptRate, falseAccept)
+	}
+
+	hi, lo := bits.Mul64(maxID, uint64(factor))
+	size, carry := bits.Add64(lo, uint64(factor), 0)
+	if hi != 0 || carry != 0 && size != 0 {
+		return 0, fmt.Errorf("%w, %d IDs with a false accept rate of %g need more than 64 bits", ErrInvalidFalseAcceptRate, maxID, falseAccept)
+	}
+
+	// A size of exactly 2^64 wraps to 0
+	return size - 1, nil
+}
+
+// NewAuthenticatedCodec creates a Codec for IDs in [0, maxID] where Decode rejects a guessed string with a
+// probability of at least 1 - falseAccept. The IDs are spread over a keyed network, so without the key
+// there's no way to tell which strings are valid other than trying them.
+func NewAuthenticatedCodec(maxID uint64, falseAccept float64, key []byte, rounds uint8, encoding *Encoding, minLength int) (*Codec, error) {
+	maxValue, err := SparseMaxValue(maxID, falseAccept)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := feistel.NewKeyedNetwork(maxValue, key, rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCodec(network, encoding, minLength, WithMaxID(maxID))
+}
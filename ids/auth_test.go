@@ -0,0 +1,161 @@
+package ids
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestAuthenticatedCodecExactFalseAcceptRate(t *testing.T) {
+	maxID := uint64(999)
+	c, err := NewAuthenticatedCodec(maxID, 0.01, testKey, 8, Base62, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every value in the sparse space is a possible guess and exactly maxID+1 of them are valid
+	accepted := 0
+	seen := make(map[uint64]struct{}, maxID+1)
+
+	for value := range uint64(100000) {
+		id, err := c.Decode(string(Base62.Append(nil, value, 0)))
+		if err != nil {
+			if !errors.Is(err, ErrForgedID) {
+				t.Fatalf("Expected ErrForgedID, got %v", err)
+			}
+			continue
+		}
+
+		accepted++
+		seen[id] = struct{}{}
+	}
+
+	if accepted != int(maxID+1) || len(seen) != int(maxID+1) {
+		t.Errorf("Expected exactly %d accepted values, got %d covering %d IDs", maxID+1, accepted, len(seen))
+	}
+
+	// The next value after the sparse space doesn't exist at all
+	if _, err := c.Decode(string(Base62.Append(nil, 100000, 0))); !errors.Is(err, ErrForgedID) {
+		t.Errorf("Expected ErrForgedID outside of the sparse space, got %v", err)
+	}
+}
+
+func TestAuthenticatedCodecRandomGuesses(t *testing.T) {
+	maxID := uint64(1 << 32)
+	falseAccept := 1e-4
+	c, err := NewAuthenticatedCodec(maxID, falseAccept, testKey, 8, Crockford32, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []uint64{0, 1, 12345, maxID} {
+		s, err := c.Encode(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded, err := c.Decode(s); err != nil || decoded != id {
+			t.Fatalf("Encoded %d to %q and decoding produced %d, %v", id, s, decoded, err)
+		}
+	}
+
+	if _, err := c.Encode(maxID + 1); !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue above maxID, got %v", err)
+	}
+
+	maxValue, err := SparseMaxValue(maxID, falseAccept)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	guesses := 200000
+	accepted := 0
+
+	for range guesses {
+		guess := string(Crockford32.Append(nil, rng.Uint64N(maxValue+1), 10))
+		if _, err := c.Decode(guess); err == nil {
+			accepted++
+		}
+	}
+
+	// Expect 20 accepted guesses, 5σ of a binomial around that is roughly 22
+	expected := float64(guesses) * falseAccept
+	if math.Abs(float64(accepted)-expected) > 5*math.Sqrt(expected) {
+		t.Errorf("Expected about %.0f accepted guesses out of %d, got %d", expected, guesses, accepted)
+	}
+}
+
+func TestSparseMaxValue(t *testing.T) {
+	tests := []struct {
+		maxID       uint64
+		falseAccept float64
+		expected    uint64
+	}{
+		{99, 1, 99},
+		{99, 0.5, 199},
+		{99, 0.3, 399},
+		{1<<32 - 1, 1.0 / (1 << 32), math.MaxUint64},
+		{math.MaxUint64, 1, math.MaxUint64},
+	}
+
+	for _, test := range tests {
+		maxValue, err := SparseMaxValue(test.maxID, test.falseAccept)
+		if err != nil || maxValue != test.expected {
+			t.Errorf("Expected SparseMaxValue(%d, %g) to be %d, got %d, %v", test.maxID, test.falseAccept, test.expected, maxValue, err)
+		}
+	}
+
+	for _, falseAccept := range []float64{0, -1, 1.5, math.NaN(), 1e-20} {
+		if _, err := SparseMaxValue(99, falseAccept); !errors.Is(err, ErrInvalidFalseAcceptRate) {
+			t.Errorf("Expected ErrInvalidFalseAcceptRate for %g, got %v", falseAccept, err)
+		}
+	}
+
+	if _, err := SparseMaxValue(1<<32, 1.0/(1<<32)); !errors.Is(err, ErrInvalidFalseAcceptRate) {
+		t.Errorf("Expected ErrInvalidFalseAcceptRate when the space doesn't fit in 64 bits, got %v", err)
+	}
+}
+
+// walkLimitMapper fails every inversion the way a network with WithMaxWalks does when it runs out of walks
+type walkLimitMapper struct{}
+
+func (walkLimitMapper) Map(index uint64) (uint64, error) {
+	return index, nil
+}
+
+func (walkLimitMapper) InvertMap(index uint64) (uint64, error) {
+	return 0, &feistel.WalkLimitError{Index: index, Steps: 1}
+}
+
+func TestDecodeKeepsMapperErrors(t *testing.T) {
+	net, err := feistel.NewNetwork(100, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCodec(net, Base62, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Decode(string(Base62.Append(nil, 101, 0)))
+	if !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) || !errors.Is(err, ErrForgedID) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue and ErrForgedID above max value, got %v", err)
+	}
+
+	c, err = NewCodec(walkLimitMapper{}, Base62, 0, WithMaxID(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var walkErr *feistel.WalkLimitError
+	if _, err := c.Decode("1"); !errors.As(err, &walkErr) || errors.Is(err, ErrForgedID) {
+		t.Errorf("Expected the *WalkLimitError without ErrForgedID, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package ids
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+)
+
+// ErrNoCodec is returned when an ObfuscatedID is encoded or decoded before SetCodec was called
+var ErrNoCodec = errors.New("ids: no codec set, call SetCodec first")
+
+// ErrUnsupportedScanType is returned when an ObfuscatedID is scanned from a database type it can't hold
+var ErrUnsupportedScanType = errors.New("ids: unsupported scan type")
+
+var defaultCodec atomic.Pointer[Codec]
+
+// SetCodec sets the Codec used by ObfuscatedID, call it once at startup before any IDs are encoded
+func SetCodec(c *Codec) {
+	defaultCodec.Store(c)
+}
+
+func codec() (*Codec, error) {
+	c := defaultCodec.Load()
+	if c == nil {
+		return nil, ErrNoCodec
+	}
+	return c, nil
+}
+
+// ObfuscatedID is an integer ID that is stored as the plain integer in a database
+// but shows up as an obfuscated string in JSON and any other text encoding
+type ObfuscatedID uint64
+
+var (
+	_ json.Marshaler   = ObfuscatedID(0)
+	_ json.Unmarshaler = (*ObfuscatedID)(nil)
+	_ driver.Valuer    = ObfuscatedID(0)
+)
+
+// String returns the obfuscated form, or a placeholder when it can't be encoded so the plain number never
+// ends up in a log line. The error isn't used either since it can hold the ID.
+func (id ObfuscatedID) String() string {
+	text, err := id.MarshalText()
+	switch {
+	case errors.Is(err, ErrNoCodec):
+		return "<ObfuscatedID: no codec>"
+	case err != nil:
+		return "<ObfuscatedID: invalid>"
+	}
+	return string(text)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (id ObfuscatedID) MarshalText() ([]byte, error) {
+	c, err := codec()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := c.Encode(uint64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (id *ObfuscatedID) UnmarshalText(text []byte) error {
+	c, err := codec()
+	if err != nil {
+		return err
+	}
+
+	value, err := c.Decode(string(text))
+	if err != nil {
+		return err
+	}
+
+	*id = ObfuscatedID(value)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (id ObfuscatedID) MarshalJSON() ([]byte, error) {
+	text, err := id.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, null leaves the ID unchanged
+func (id *ObfuscatedID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return id.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer, the plain ID is stored
+func (id ObfuscatedID) Value() (driver.Value, error) {
+	if uint64(id) > math.MaxInt64 {
+		return nil, fmt.Errorf("ids: %d doesn't fit in an int64", uint64(id))
+	}
+	return int64(id), nil
+}
+
+// Scan implements sql.Scanner for integer columns
+func (id *ObfuscatedID) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("%w, negative ID %d", ErrUnsupportedScanType, v)
+		}
+		*id = ObfuscatedID(v)
+	case uint64:
+		*id = ObfuscatedID(v)
+	case []byte:
+		return id.scanString(string(v))
+	case string:
+		return id.scanString(v)
+	default:
+		return fmt.Errorf("%w, %T", ErrUnsupportedScanType, src)
+	}
+
+	return nil
+}
+
+func (id *ObfuscatedID) scanString(s string) error {
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrUnsupportedScanType, err)
+	}
+
+	*id = ObfuscatedID(value)
+	return nil
+}
@@ -0,0 +1,114 @@
+package ids
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+type user struct {
+	ID   ObfuscatedID `json:"id"`
+	Name string       `json:"name"`
+}
+
+func TestObfuscatedIDJSON(t *testing.T) {
+	c := newCodec(t, Base62, 8)
+	SetCodec(c)
+	t.Cleanup(func() { SetCodec(nil) })
+
+	data, err := json.Marshal(user{ID: 17, Name: "ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := c.Encode(17)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"id":"`+expected+`","name":"ada"}` {
+		t.Fatalf("Unexpected JSON %s", data)
+	}
+
+	var decoded user
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.ID != 17 {
+		t.Errorf("Marshaled 17 to %s and unmarshaling produced %d", data, decoded.ID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":null}`), &decoded); err != nil || decoded.ID != 17 {
+		t.Errorf("Expected null to leave the ID unchanged, got %d, %v", decoded.ID, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":17}`), &decoded); err == nil {
+		t.Errorf("Expected a plain number to be rejected")
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":"!!"}`), &decoded); !errors.Is(err, ErrInvalidCharacter) && !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected an invalid ID to be rejected, got %v", err)
+	}
+
+	if ObfuscatedID(17).String() != expected {
+		t.Errorf("Expected String to return %q, got %q", expected, ObfuscatedID(17).String())
+	}
+}
+
+func TestObfuscatedIDNoCodec(t *testing.T) {
+	SetCodec(nil)
+
+	if _, err := json.Marshal(ObfuscatedID(1)); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("Expected ErrNoCodec, got %v", err)
+	}
+
+	if s := fmt.Sprint(ObfuscatedID(12345)); s != "<ObfuscatedID: no codec>" {
+		t.Errorf("Expected String to return a placeholder instead of the number, got %q", s)
+	}
+
+	net, err := feistel.NewNetwork(Base62.MaxValue(8), 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCodec(net, Base62, 8, WithMaxID(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCodec(c)
+	t.Cleanup(func() { SetCodec(nil) })
+
+	if s := fmt.Sprint(ObfuscatedID(12345)); s != "<ObfuscatedID: invalid>" {
+		t.Errorf("Expected String to return a placeholder for an ID the codec rejects, got %q", s)
+	}
+}
+
+func TestObfuscatedIDSQL(t *testing.T) {
+	value, err := ObfuscatedID(42).Value()
+	if err != nil || value != int64(42) {
+		t.Errorf("Expected Value to return int64 42, got %v, %v", value, err)
+	}
+
+	if _, err := ObfuscatedID(math.MaxUint64).Value(); err == nil {
+		t.Errorf("Expected an error for an ID that doesn't fit in an int64")
+	}
+
+	for _, src := range []any{int64(42), uint64(42), []byte("42"), "42"} {
+		var id ObfuscatedID
+		if err := id.Scan(src); err != nil || id != 42 {
+			t.Errorf("Expected to scan %v (%T) as 42, got %d, %v", src, src, id, err)
+		}
+	}
+
+	for _, src := range []any{int64(-1), nil, 4.2, "abc"} {
+		var id ObfuscatedID
+		if err := id.Scan(src); !errors.Is(err, ErrUnsupportedScanType) {
+			t.Errorf("Expected ErrUnsupportedScanType for %v (%T), got %v", src, src, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package ids turns sequential integer IDs into short opaque strings and back
+// A Codec maps the ID with a feistel.Mapper and writes the result in base62 or Crockford base32
+// ObfuscatedID does the same transparently for JSON, text encodings and database/sql
+package ids
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mormehtar/feistel"
+)
+
+// ErrInvalidCharacter is returned when a string has a character that isn't part of the encoding
+var ErrInvalidCharacter = errors.New("ids: invalid character")
+
+// ErrInvalidLength is returned when a string is shorter than the minimum length, isn't in its shortest form,
+// or is too long to hold a uint64
+var ErrInvalidLength = errors.New("ids: invalid length")
+
+// ErrInvalidEncoding is returned when an alphabet has fewer than 2 or more than 256 unique ASCII characters
+var ErrInvalidEncoding = errors.New("ids: invalid encoding")
+
+// Encoding is an alphabet used to write numbers, the first character is 0
+type Encoding struct {
+	alphabet string
+	decode   [256]int16
+}
+
+// Base62 uses digits, upper case and lower case letters and is case sensitive
+var Base62 = MustEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+
+// Crockford32 is Douglas Crockford's base32, decoding is case insensitive, O is read as 0 and I and L are read as 1
+var Crockford32 = MustEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ").
+	withAliases(map[byte]byte{'O': '0', 'o': '0', 'I': '1', 'i': '1', 'L': '1', 'l': '1'}).
+	withLowerCase()
+
+// NewEncoding creates an Encoding from an alphabet of unique ASCII characters
+func NewEncoding(alphabet string) (*Encoding, error) {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return nil, fmt.Errorf("%w, alphabet: %q", ErrInvalidEncoding, alphabet)
+	}
+
+	e := &Encoding{alphabet: alphabet}
+	for i := range e.decode {
+		e.decode[i] = -1
+	}
+
+	for i := range len(alphabet) {
+		c := alphabet[i]
+		if c >= 0x80 || e.decode[c] != -1 {
+			return nil, fmt.Errorf("%w, %q is repeated or not ASCII", ErrInvalidEncoding, c)
+		}
+		e.decode[c] = int16(i)
+	}
+
+	return e, nil
+}
+
+// MustEncoding is like NewEncoding but panics on an invalid alphabet
+func MustEncoding(alphabet string) *Encoding {
+	e, err := NewEncoding(alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func (e *Encoding) withAliases(aliases map[byte]byte) *Encoding {
+	for from, to := range aliases {
+		e.decode[from] = e.decode[to]
+	}
+	return e
+}
+
+func (e *Encoding) withLowerCase() *Encoding {
+	for c := byte('A'); c <= 'Z'; c++ {
+		if e.decode[c+'a'-'A'] == -1 {
+			e.decode[c+'a'-'A'] = e.decode[c]
+		}
+	}
+	return e
+}
+
+// Radix returns the number of characters in the alphabet
+func (e *Encoding) Radix() uint64 {
+	return uint64(len(e.alphabet))
+}
+
+// MaxValue returns the largest value that can be written with length characters, or the largest uint64
+// if length characters can hold more than that. Use it as the max value of the Network to get IDs that
+// are always exactly length characters long.
+func (e *Encoding) MaxValue(length int) uint64 {
+	value := uint64(1)
+	for range length {
+		if value > ^uint64(0)/e.Radix() {
+			return ^uint64(0)
+		}
+		value *= e.Radix()
+	}
+	return value - 1
+}
+
+// maxLength is the number of characters needed to write the largest uint64
+func (e *Encoding) maxLength() int {
+	length := 0
+	for value := ^uint64(0); value > 0; value /= e.Radix() {
+		length++
+	}
+	return length
+}
+
+// Append writes value with at least minLength characters, padding with the first character of the alphabet
+func (e *Encoding) Append(dst []byte, value uint64, minLength int) []byte {
+	// 64 characters are enough for the largest uint64 in base 2, the padding goes straight to dst
+	var buf [64]byte
+	i := len(buf)
+
+	for {
+		i--
+		buf[i] = e.alphabet[value%e.Radix()]
+		value /= e.Radix()
+
+		if value == 0 {
+			break
+		}
+	}
+
+	for range minLength - (len(buf) - i) {
+		dst = append(dst, e.alphabet[0])
+	}
+
+	return append(dst, buf[i:]...)
+}
+
+// Parse performs an inversion of Append, it only accepts the shortest form that is at least minLength characters long
+// so every value is written by a single string of the alphabet. Aliases are decoded too, so with Crockford32 lower case
+// and O, I and L give other strings for the same value, write with Append if you need to compare strings.
+func (e *Encoding) Parse(s string, minLength int) (uint64, error) {
+	if len(s) < minLength || len(s) > max(e.maxLength(), minLength) || len(s) == 0 {
+		return 0, fmt.Errorf("%w, %q", ErrInvalidLength, s)
+	}
+
+	if len(s) > max(minLength, 1) && e.decode[s[0]] == 0 {
+		return 0, fmt.Errorf("%w, %q has leading zeros", ErrInvalidLength, s)
+	}
+
+	var value uint64
+	for i := range len(s) {
+		digit := e.decode[s[i]]
+		if digit < 0 {
+			return 0, fmt.Errorf("%w, %q in %q", ErrInvalidCharacter, s[i], s)
+		}
+
+		if value > (^uint64(0)-uint64(digit))/e.Radix() {
+			return 0, fmt.Errorf("%w, %q doesn't fit in a uint64", ErrInvalidLength, s)
+		}
+		value = value*e.Radix() + uint64(digit)
+	}
+
+	return value, nil
+}
+
+// Codec maps IDs with a feistel.Mapper and writes them with an Encoding
+type Codec struct {
+	mapper    feistel.Mapper
+	encoding  *Encoding
+	minLength int
+	maxID     uint64
+}
+
+// Option is an optional setting for a Codec
+type Option func(*Codec)
+
+// NewCodec creates a Codec, mapper is usually a feistel.Network over the ID space.
+// Encoded IDs are padded to minLength characters, creating the Network with encoding.MaxValue(minLength)
+// as its max value makes every ID exactly minLength characters long.
+func NewCodec(mapper feistel.Mapper, encoding *Encoding, minLength int, opts ...Option) (*Codec, error) {
+	if minLength < 0 || minLength > encoding.maxLength() {
+		return nil, fmt.Errorf("%w, minLength: %d, maxLength: %d", ErrInvalidLength, minLength, encoding.maxLength())
+	}
+
+	c := &Codec{mapper: mapper, encoding: encoding, minLength: minLength, maxID: ^uint64(0)}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Encode maps id and writes the result
+func (c *Codec) Encode(id uint64) (string, error) {
+	if id > c.maxID {
+		return "", fmt.Errorf("%w, id: %d, maxID: %d", feistel.ErrIndexGreatThanMaxValue, id, c.maxID)
+	}
+
+	mapped, err := c.mapper.Map(id)
+	if err != nil {
+		return "", err
+	}
+
+	return string(c.encoding.Append(nil, mapped, c.minLength)), nil
+}
+
+// Decode performs an inversion of Encode
+func (c *Codec) Decode(s string) (uint64, error) {
+	value, err := c.encoding.Parse(s, c.minLength)
+	if err != nil {
+		return 0, err
+	}
+
+	// A value above the max value of the mapper can't have come from Encode either
+	id, err := c.mapper.InvertMap(value)
+	if errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		return 0, fmt.Errorf("%w: %w", ErrForgedID, err)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if id > c.maxID {
+		return 0, fmt.Errorf("%w, %q", ErrForgedID, s)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,158 @@
+package ids
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+func newCodec(t *testing.T, encoding *Encoding, minLength int) *Codec {
+	t.Helper()
+
+	net, err := feistel.NewNetwork(encoding.MaxValue(minLength), 42, 8, feistel.WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCodec(net, encoding, minLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, encoding := range map[string]*Encoding{"base62": Base62, "crockford": Crockford32} {
+		t.Run(name, func(t *testing.T) {
+			c := newCodec(t, encoding, 6)
+			seen := make(map[string]struct{})
+
+			for _, id := range []uint64{0, 1, 2, 1000, encoding.MaxValue(6), encoding.MaxValue(6) + 1, 1 << 40, 1 << 60} {
+				s, err := c.Encode(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(s) < 6 {
+					t.Fatalf("Encoded %d to %q which is shorter than 6", id, s)
+				}
+
+				if _, ok := seen[s]; ok {
+					t.Fatalf("%q was produced twice", s)
+				}
+				seen[s] = struct{}{}
+
+				decoded, err := c.Decode(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if decoded != id {
+					t.Fatalf("Encoded %d to %q and decoding produced %d", id, s, decoded)
+				}
+			}
+		})
+	}
+}
+
+func TestCodecFixedLength(t *testing.T) {
+	c := newCodec(t, Base62, 5)
+
+	for id := range Base62.MaxValue(5) / 1000 {
+		s, err := c.Encode(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(s) != 5 {
+			t.Fatalf("Encoded %d to %q which isn't 5 characters", id, s)
+		}
+	}
+}
+
+func TestCrockfordAliases(t *testing.T) {
+	c := newCodec(t, Crockford32, 8)
+
+	s, err := c.Encode(12345)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lower := make([]byte, len(s))
+	for i := range len(s) {
+		lower[i] = s[i]
+		if s[i] >= 'A' && s[i] <= 'Z' {
+			lower[i] = s[i] + 'a' - 'A'
+		}
+	}
+
+	if decoded, err := c.Decode(string(lower)); err != nil || decoded != 12345 {
+		t.Errorf("Expected %q to decode to 12345, got %d, %v", lower, decoded, err)
+	}
+
+	if value, err := Crockford32.Parse("1OIL", 0); err != nil || value != 1*32*32*32+0+32+1 {
+		t.Errorf("Expected 1OIL to be read as 1011, got %d, %v", value, err)
+	}
+
+	if _, err := Crockford32.Parse("1U", 0); !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("Expected ErrInvalidCharacter for U, got %v", err)
+	}
+}
+
+func TestEncodingEdgeCases(t *testing.T) {
+	if s := string(Base62.Append(nil, 0, 0)); s != "0" {
+		t.Errorf("Expected 0 to be written as \"0\", got %q", s)
+	}
+
+	if s := string(Base62.Append(nil, 61, 3)); s != "00z" {
+		t.Errorf("Expected 61 padded to 3 to be \"00z\", got %q", s)
+	}
+
+	padded := string(Base62.Append([]byte("id-"), ^uint64(0), 100))
+	if len(padded) != 103 || padded[:5] != "id-00" {
+		t.Errorf("Expected the largest uint64 padded to 100 characters after the prefix, got %q", padded)
+	}
+
+	if value, err := Base62.Parse(padded[3:], 100); err != nil || value != ^uint64(0) {
+		t.Errorf("Expected %q to parse to the largest uint64, got %d, %v", padded[3:], value, err)
+	}
+
+	max := string(Base62.Append(nil, ^uint64(0), 0))
+	if value, err := Base62.Parse(max, 0); err != nil || value != ^uint64(0) {
+		t.Errorf("Expected %q to parse to the largest uint64, got %d, %v", max, value, err)
+	}
+
+	if _, err := Base62.Parse("zzzzzzzzzzz", 0); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength for an overflow, got %v", err)
+	}
+
+	if value, err := Base62.Parse("0", 0); err != nil || value != 0 {
+		t.Errorf("Expected \"0\" to parse to 0, got %d, %v", value, err)
+	}
+
+	for _, s := range []string{"", "0z"} {
+		if _, err := Base62.Parse(s, 1); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("Expected ErrInvalidLength for %q, got %v", s, err)
+		}
+	}
+
+	if _, err := Base62.Parse("ab", 3); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength for a string shorter than the minimum, got %v", err)
+	}
+
+	for _, alphabet := range []string{"a", "abca", "abcé"} {
+		if _, err := NewEncoding(alphabet); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("Expected ErrInvalidEncoding for %q, got %v", alphabet, err)
+		}
+	}
+
+	net, err := feistel.NewNetwork(100, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewCodec(net, Base62, 12); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength for a min length above 11, got %v", err)
+	}
+}
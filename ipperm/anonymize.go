@@ -0,0 +1,86 @@
+package ipperm
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/mormehtar/feistel"
+)
+
+// ErrInvalidAddress is returned when an address isn't valid
+var ErrInvalidAddress = errors.New("ipperm: invalid address")
+
+// Anonymizer pseudonymizes addresses in a prefix-preserving way like Crypto-PAn, two addresses that share
+// their first n bits still share exactly their first n bits after mapping. Each bit is flipped by a keyed
+// AES bit of the bits before it, so knowing the key is enough to reverse it and nothing else is.
+type Anonymizer struct {
+	aes *feistel.AES
+}
+
+// NewAnonymizer creates an Anonymizer, key has to be 16, 24 or 32 bytes
+func NewAnonymizer(key []byte) (*Anonymizer, error) {
+	aes, err := feistel.NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Anonymizer{aes: aes}, nil
+}
+
+// Anonymize maps addr to its pseudonym, IPv4 addresses stay IPv4 and the zone is dropped
+func (a *Anonymizer) Anonymize(addr netip.Addr) (netip.Addr, error) {
+	return a.encode(addr, false)
+}
+
+// Deanonymize performs an inversion of Anonymize
+func (a *Anonymizer) Deanonymize(addr netip.Addr) (netip.Addr, error) {
+	return a.encode(addr, true)
+}
+
+// ipv4Mapped is ::ffff:0:0, IPv4 addresses are mapped as the last 32 bits of it so they never share
+// a pseudonym bit with an unrelated IPv6 prefix
+var ipv4Mapped = uint128{0, 0xffff << 32}
+
+func (a *Anonymizer) encode(addr netip.Addr, invert bool) (netip.Addr, error) {
+	if !addr.IsValid() {
+		return netip.Addr{}, fmt.Errorf("%w, %v", ErrInvalidAddress, addr)
+	}
+
+	is4 := addr.Is4()
+	value := fromAddr(addr)
+	start := 0
+
+	if is4 {
+		value.lo |= ipv4Mapped.lo
+		start = 96
+	}
+
+	// original is the address before anonymization, as far as it's known, and result is the other side
+	original := value
+	result := value
+
+	for i := start; i < 128; i++ {
+		flip := a.bit(original, i)
+
+		if invert {
+			original = original.setBit(i, value.bit(i)^flip)
+			result = original
+		} else {
+			result = result.setBit(i, value.bit(i)^flip)
+		}
+	}
+
+	if is4 {
+		result.lo &= 1<<32 - 1
+	}
+
+	return toAddr(result, is4), nil
+}
+
+// bit returns the pseudo-random bit for position i of an address that starts with the first i bits of prefix.
+// The prefix is followed by a single 1 bit and zeros so that every prefix length gives a different AES block.
+func (a *Anonymizer) bit(prefix uint128, i int) uint64 {
+	block := prefix.keepTop(i).setBit(i, 1)
+	return a.aes.Round(block.hi, block.lo, 2)
+}
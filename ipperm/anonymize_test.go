@@ -0,0 +1,186 @@
+package ipperm
+
+import (
+	"errors"
+	"math/bits"
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func commonPrefixLength(a, b netip.Addr) int {
+	x, y := fromAddr(a), fromAddr(b)
+	if a.Is4() {
+		return bits.LeadingZeros32(uint32(x.lo ^ y.lo))
+	}
+
+	if x.hi != y.hi {
+		return bits.LeadingZeros64(x.hi ^ y.hi)
+	}
+	return 64 + bits.LeadingZeros64(x.lo^y.lo)
+}
+
+func randomAddr(rng *rand.Rand, is4 bool) netip.Addr {
+	if is4 {
+		return toAddr(uint128{0, rng.Uint64() & (1<<32 - 1)}, true)
+	}
+	return toAddr(uint128{rng.Uint64(), rng.Uint64()}, false)
+}
+
+// flipFrom keeps the first n bits of addr and flips bit n, so the result shares exactly n bits with addr
+func flipFrom(rng *rand.Rand, addr netip.Addr, n int) netip.Addr {
+	other := randomAddr(rng, addr.Is4())
+	offset := 0
+	if addr.Is4() {
+		offset = 96
+	}
+
+	value, random := fromAddr(addr), fromAddr(other)
+	for i := offset + n; i < 128; i++ {
+		bit := random.bit(i)
+		if i == offset+n {
+			bit = value.bit(i) ^ 1
+		}
+		value = value.setBit(i, bit)
+	}
+
+	return toAddr(value, addr.Is4())
+}
+
+func TestAnonymizerPreservesPrefixes(t *testing.T) {
+	a, err := NewAnonymizer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for _, is4 := range []bool{true, false} {
+		bitLen := 128
+		if is4 {
+			bitLen = 32
+		}
+
+		for range 200 {
+			first := randomAddr(rng, is4)
+
+			for n := range bitLen {
+				second := flipFrom(rng, first, n)
+
+				if commonPrefixLength(first, second) != n {
+					t.Fatalf("Test setup produced %v and %v which don't share exactly %d bits", first, second, n)
+				}
+
+				mappedFirst, err := a.Anonymize(first)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				mappedSecond, err := a.Anonymize(second)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mappedFirst.Is4() != is4 {
+					t.Fatalf("Mapped %v to %v which changed the address family", first, mappedFirst)
+				}
+
+				if shared := commonPrefixLength(mappedFirst, mappedSecond); shared != n {
+					t.Fatalf("%v and %v share %d bits but were mapped to %v and %v which share %d", first, second, n, mappedFirst, mappedSecond, shared)
+				}
+			}
+		}
+	}
+}
+
+func TestAnonymizerSubnets(t *testing.T) {
+	a, err := NewAnonymizer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subnet := netip.MustParsePrefix("192.0.2.0/24")
+	var mappedSubnet netip.Prefix
+
+	for addr := subnet.Addr(); subnet.Contains(addr); addr = addr.Next() {
+		mapped, err := a.Anonymize(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prefix := netip.PrefixFrom(mapped, 24).Masked()
+		if !mappedSubnet.IsValid() {
+			mappedSubnet = prefix
+		}
+
+		if prefix != mappedSubnet {
+			t.Fatalf("Expected every address of %v to be mapped into %v, %v was mapped to %v", subnet, mappedSubnet, addr, mapped)
+		}
+	}
+
+	if mappedSubnet == subnet {
+		t.Errorf("Expected %v to be mapped to a different subnet", subnet)
+	}
+}
+
+func TestAnonymizerInverse(t *testing.T) {
+	a, err := NewAnonymizer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewAnonymizer([]byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	addrs := []netip.Addr{netip.MustParseAddr("0.0.0.0"), netip.MustParseAddr("255.255.255.255"), netip.MustParseAddr("::"), netip.MustParseAddr("::ffff:10.0.0.1")}
+	for range 1000 {
+		addrs = append(addrs, randomAddr(rng, true), randomAddr(rng, false))
+	}
+
+	differs := 0
+	for _, addr := range addrs {
+		mapped, err := a.Anonymize(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inverted, err := a.Deanonymize(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != addr {
+			t.Fatalf("Mapped %v to %v and inversion produced %v", addr, mapped, inverted)
+		}
+
+		if mappedOther, _ := other.Anonymize(addr); mappedOther != mapped {
+			differs++
+		}
+	}
+
+	if differs < len(addrs)-2 {
+		t.Errorf("Expected different keys to give different pseudonyms, only %d of %d differ", differs, len(addrs))
+	}
+}
+
+func TestAnonymizerErrors(t *testing.T) {
+	if _, err := NewAnonymizer([]byte("short")); !errors.Is(err, feistel.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+
+	a, err := NewAnonymizer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Anonymize(netip.Addr{}); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected ErrInvalidAddress, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Package ipperm visits every address in a set of IP ranges, optionally crossed with a list of ports,
+// exactly once in a pseudo-random order. It's meant for spreading scans and probes across networks
+// the way masscan does, excluded ranges are subtracted arithmetically so they're never materialized.
+package ipperm
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+	"net/netip"
+	"sort"
+
+	"github.com/mormehtar/feistel"
+)
+
+// ErrInvalidPrefix is returned when a prefix isn't valid
+var ErrInvalidPrefix = errors.New("ipperm: invalid prefix")
+
+// ErrInvalidPorts is returned when the port list repeats a port
+var ErrInvalidPorts = errors.New("ipperm: invalid ports")
+
+// ErrEmpty is returned when nothing is left after the exclusions
+var ErrEmpty = errors.New("ipperm: no addresses left after exclusions")
+
+// ErrTooManyTargets is returned when there are more than 2^64 targets
+var ErrTooManyTargets = errors.New("ipperm: too many targets, their positions have to fit in a uint64")
+
+// ErrNotInSet is returned by Index when the target isn't one of the targets of the Permutation
+var ErrNotInSet = errors.New("ipperm: target is not in the set")
+
+// block is a span of addresses with the number of addresses that come before it,
+// last is the number of addresses minus one so a block can hold 2^64 of them
+type block struct {
+	first  uint128
+	last   uint64
+	offset uint64
+	is4    bool
+}
+
+// Permutation is a pseudo-random order over every (address, port) target
+type Permutation struct {
+	network   *feistel.Network
+	blocks    []block
+	ports     []uint16
+	portIndex map[uint16]uint64
+	// lastAddress and lastIndex are the counts minus one so exactly 2^64 of either fit
+	lastAddress uint64
+	lastIndex   uint64
+}
+
+// New creates a Permutation over every address in include that isn't in exclude, crossed with ports.
+// Without ports each target has port 0. IPv4 and IPv6 prefixes can be mixed, an IPv4-mapped IPv6 prefix is
+// treated as IPv6. seed, rounds and opts are passed to feistel.NewNetwork.
+func New(include, exclude []netip.Prefix, ports []uint16, seed uint64, rounds uint8, opts ...feistel.Option) (*Permutation, error) {
+	p := &Permutation{
+		ports:     ports,
+		portIndex: make(map[uint16]uint64, len(ports)),
+	}
+
+	for i, port := range ports {
+		if _, ok := p.portIndex[port]; ok {
+			return nil, fmt.Errorf("%w, port %d is repeated", ErrInvalidPorts, port)
+		}
+		p.portIndex[port] = uint64(i)
+	}
+
+	for _, is4 := range []bool{true, false} {
+		includeSpans, err := familySpans(include, is4)
+		if err != nil {
+			return nil, err
+		}
+
+		excludeSpans, err := familySpans(exclude, is4)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range subtractSpans(includeSpans, excludeSpans) {
+			last := s.last.sub(s.first)
+			if last.hi != 0 {
+				return nil, ErrTooManyTargets
+			}
+
+			// The next block starts right after the last address so far, which wraps once there are 2^64 of them
+			var offset uint64
+			if len(p.blocks) > 0 {
+				var carry uint64
+				if offset, carry = bits.Add64(p.lastAddress, 1, 0); carry != 0 {
+					return nil, ErrTooManyTargets
+				}
+			}
+
+			var carry uint64
+			if p.lastAddress, carry = bits.Add64(offset, last.lo, 0); carry != 0 {
+				return nil, ErrTooManyTargets
+			}
+
+			p.blocks = append(p.blocks, block{first: s.first, last: last.lo, offset: offset, is4: is4})
+		}
+	}
+
+	if len(p.blocks) == 0 {
+		return nil, ErrEmpty
+	}
+
+	// The last target is the last port of the last address
+	numPorts := uint64(max(len(ports), 1))
+	hi, lastIndex := bits.Mul64(p.lastAddress, numPorts)
+	lastIndex, carry := bits.Add64(lastIndex, numPorts-1, 0)
+	if hi != 0 || carry != 0 {
+		return nil, ErrTooManyTargets
+	}
+	p.lastIndex = lastIndex
+
+	network, err := feistel.NewNetwork(lastIndex, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.network = network
+	return p, nil
+}
+
+func familySpans(prefixes []netip.Prefix, is4 bool) ([]span, error) {
+	var spans []span
+
+	for _, prefix := range prefixes {
+		if !prefix.IsValid() {
+			return nil, fmt.Errorf("%w, %v", ErrInvalidPrefix, prefix)
+		}
+
+		if prefix.Addr().Is4() == is4 {
+			spans = append(spans, prefixSpan(prefix))
+		}
+	}
+
+	return mergeSpans(spans), nil
+}
+
+// Size returns the number of targets, exactly 2^64 targets wraps to 0
+func (p *Permutation) Size() uint64 {
+	return p.lastIndex + 1
+}
+
+// Addresses returns the number of addresses left after the exclusions, exactly 2^64 addresses wraps to 0
+func (p *Permutation) Addresses() uint64 {
+	return p.lastAddress + 1
+}
+
+// At returns the target at position index of the order
+func (p *Permutation) At(index uint64) (netip.AddrPort, error) {
+	if index > p.lastIndex {
+		return netip.AddrPort{}, fmt.Errorf("%w, index: %d, maxSize: %d", feistel.ErrIndexGreatThanMaxValue, index, p.lastIndex)
+	}
+
+	target, err := p.network.Map(index)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+
+	return p.target(target), nil
+}
+
+// Index performs an inversion of At
+func (p *Permutation) Index(target netip.AddrPort) (uint64, error) {
+	port := uint64(0)
+	if len(p.ports) > 0 {
+		var ok bool
+		if port, ok = p.portIndex[target.Port()]; !ok {
+			return 0, fmt.Errorf("%w, port %d", ErrNotInSet, target.Port())
+		}
+	} else if target.Port() != 0 {
+		return 0, fmt.Errorf("%w, there are no ports but got %d", ErrNotInSet, target.Port())
+	}
+
+	address, ok := p.addressIndex(target.Addr())
+	if !ok {
+		return 0, fmt.Errorf("%w, address %v", ErrNotInSet, target.Addr())
+	}
+
+	return p.network.InvertMap(address*uint64(max(len(p.ports), 1)) + port)
+}
+
+// All returns an iterator over every target in order paired with its position
+func (p *Permutation) All() iter.Seq2[uint64, netip.AddrPort] {
+	return func(yield func(uint64, netip.AddrPort) bool) {
+		for index, target := range p.network.All() {
+			if !yield(index, p.target(target)) {
+				return
+			}
+		}
+	}
+}
+
+func (p *Permutation) target(target uint64) netip.AddrPort {
+	numPorts := uint64(max(len(p.ports), 1))
+	address := target / numPorts
+
+	i := sort.Search(len(p.blocks), func(i int) bool {
+		return p.blocks[i].offset > address
+	}) - 1
+	b := p.blocks[i]
+
+	var port uint16
+	if len(p.ports) > 0 {
+		port = p.ports[target%numPorts]
+	}
+
+	return netip.AddrPortFrom(toAddr(b.first.add(address-b.offset), b.is4), port)
+}
+
+func (p *Permutation) addressIndex(addr netip.Addr) (uint64, bool) {
+	value := fromAddr(addr)
+	is4 := addr.Is4()
+
+	// Blocks are sorted with IPv4 first and by address within a family, find the last one starting at or before value
+	i := sort.Search(len(p.blocks), func(i int) bool {
+		b := p.blocks[i]
+		if b.is4 != is4 {
+			return !b.is4
+		}
+		return b.first.compare(value) > 0
+	}) - 1
+
+	if i < 0 || p.blocks[i].is4 != is4 {
+		return 0, false
+	}
+
+	b := p.blocks[i]
+	if delta := value.sub(b.first); delta.hi == 0 && delta.lo <= b.last {
+		return b.offset + delta.lo, true
+	}
+
+	return 0, false
+}
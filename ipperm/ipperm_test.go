@@ -0,0 +1,205 @@
+package ipperm
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/mormehtar/feistel"
+)
+
+func prefixes(t *testing.T, values ...string) []netip.Prefix {
+	t.Helper()
+
+	result := make([]netip.Prefix, len(values))
+	for i, value := range values {
+		result[i] = netip.MustParsePrefix(value)
+	}
+	return result
+}
+
+func TestPermutationVisitsEveryTargetOnce(t *testing.T) {
+	include := prefixes(t, "10.0.0.0/24", "10.0.1.0/30", "10.0.0.0/25", "2001:db8::/126")
+	exclude := prefixes(t, "10.0.0.128/25", "10.0.0.5/32", "192.168.0.0/16")
+	ports := []uint16{80, 443}
+
+	p, err := New(include, exclude, ports, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 127 + 4 IPv4 addresses and 4 IPv6 addresses
+	if p.Addresses() != 135 || p.Size() != 270 {
+		t.Fatalf("Expected 135 addresses and 270 targets, got %d and %d", p.Addresses(), p.Size())
+	}
+
+	seen := make(map[netip.AddrPort]struct{})
+
+	for index, target := range p.All() {
+		if _, ok := seen[target]; ok {
+			t.Fatalf("Target %v was visited twice", target)
+		}
+		seen[target] = struct{}{}
+
+		inInclude := false
+		for _, prefix := range include {
+			inInclude = inInclude || prefix.Contains(target.Addr())
+		}
+
+		for _, prefix := range exclude {
+			if prefix.Contains(target.Addr()) {
+				t.Fatalf("Target %v is excluded by %v", target, prefix)
+			}
+		}
+
+		if !inInclude || target.Port() != 80 && target.Port() != 443 {
+			t.Fatalf("Target %v isn't one of the targets", target)
+		}
+
+		at, err := p.At(index)
+		if err != nil || at != target {
+			t.Fatalf("Expected At(%d) to be %v, got %v, %v", index, target, at, err)
+		}
+
+		inverted, err := p.Index(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != index {
+			t.Fatalf("Mapped %d to %v and inversion produced %d", index, target, inverted)
+		}
+	}
+
+	if len(seen) != 270 {
+		t.Errorf("Expected 270 targets, got %d", len(seen))
+	}
+}
+
+func TestPermutationWithoutPorts(t *testing.T) {
+	p, err := New(prefixes(t, "192.0.2.0/29"), nil, nil, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[netip.Addr]struct{})
+	for _, target := range p.All() {
+		if target.Port() != 0 {
+			t.Fatalf("Expected port 0 without a port list, got %v", target)
+		}
+		seen[target.Addr()] = struct{}{}
+	}
+
+	if len(seen) != 8 {
+		t.Errorf("Expected all 8 addresses, got %d", len(seen))
+	}
+
+	if _, err := p.Index(netip.MustParseAddrPort("192.0.2.1:80")); !errors.Is(err, ErrNotInSet) {
+		t.Errorf("Expected ErrNotInSet for a port, got %v", err)
+	}
+}
+
+func TestPermutationLargeRanges(t *testing.T) {
+	include := prefixes(t, "0.0.0.0/0", "2001:db8::/66")
+	exclude := prefixes(t, "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8", "2001:db8::/96")
+
+	p, err := New(include, exclude, []uint16{22, 80, 443}, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAddresses := uint64(1<<32-(1<<24+1<<20+1<<16+1<<24)) + 1<<62 - 1<<32
+	if p.Addresses() != expectedAddresses {
+		t.Fatalf("Expected %d addresses, got %d", expectedAddresses, p.Addresses())
+	}
+
+	for _, index := range []uint64{0, 1, 1 << 32, p.Size() / 2, p.Size() - 1} {
+		target, err := p.At(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, prefix := range exclude {
+			if prefix.Contains(target.Addr()) {
+				t.Fatalf("Target %v is excluded by %v", target, prefix)
+			}
+		}
+
+		if inverted, err := p.Index(target); err != nil || inverted != index {
+			t.Fatalf("Mapped %d to %v and inversion produced %d, %v", index, target, inverted, err)
+		}
+	}
+
+	for _, target := range []string{"10.1.2.3:80", "[2001:db8::1]:80", "[2001:db9::1]:80", "8.8.8.8:8080"} {
+		if _, err := p.Index(netip.MustParseAddrPort(target)); !errors.Is(err, ErrNotInSet) {
+			t.Errorf("Expected ErrNotInSet for %s, got %v", target, err)
+		}
+	}
+
+	if _, err := p.At(p.Size()); !errors.Is(err, feistel.ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		include  []netip.Prefix
+		exclude  []netip.Prefix
+		ports    []uint16
+		expected error
+	}{
+		{prefixes(t, "2001:db8::/64", "10.0.0.0/32"), nil, nil, ErrTooManyTargets},
+		{prefixes(t, "2001:db8::/63"), nil, nil, ErrTooManyTargets},
+		{prefixes(t, "::/0"), nil, nil, ErrTooManyTargets},
+		{prefixes(t, "2001:db8::/65"), nil, []uint16{1, 2, 3}, ErrTooManyTargets},
+		{prefixes(t, "10.0.0.0/8"), prefixes(t, "10.0.0.0/7"), nil, ErrEmpty},
+		{nil, nil, nil, ErrEmpty},
+		{prefixes(t, "10.0.0.0/8"), nil, []uint16{80, 80}, ErrInvalidPorts},
+		{[]netip.Prefix{{}}, nil, nil, ErrInvalidPrefix},
+	}
+
+	for _, test := range tests {
+		if _, err := New(test.include, test.exclude, test.ports, 1, 8); !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for %v minus %v, got %v", test.expected, test.include, test.exclude, err)
+		}
+	}
+}
+
+func TestFullUint64Targets(t *testing.T) {
+	tests := []struct {
+		include []netip.Prefix
+		ports   []uint16
+	}{
+		{prefixes(t, "2001:db8::/64"), nil},
+		{prefixes(t, "2001:db8::/65"), []uint16{80, 443}},
+		{prefixes(t, "2001:db8::/65", "2001:db8:0:0:8000::/65"), nil},
+	}
+
+	for _, test := range tests {
+		p, err := New(test.include, nil, test.ports, 42, 8)
+		if err != nil {
+			t.Fatalf("Expected 2^64 targets for %v with ports %v to fit, got %v", test.include, test.ports, err)
+		}
+
+		// 2^64 wraps to 0
+		if p.Size() != 0 {
+			t.Errorf("Expected a size of 0 for 2^64 targets, got %d", p.Size())
+		}
+
+		for _, index := range []uint64{0, 1, 1 << 63, ^uint64(0)} {
+			target, err := p.At(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.include[0].Contains(target.Addr()) && !test.include[len(test.include)-1].Contains(target.Addr()) {
+				t.Fatalf("Target %v isn't in %v", target, test.include)
+			}
+
+			inverted, err := p.Index(target)
+			if err != nil || inverted != index {
+				t.Fatalf("Target %v at %d has index %d, %v", target, index, inverted, err)
+			}
+		}
+	}
+}
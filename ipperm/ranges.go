@@ -0,0 +1,180 @@
+package ipperm
+
+import (
+	"cmp"
+	"encoding/binary"
+	"net/netip"
+	"slices"
+)
+
+// uint128 holds an address of either family, IPv4 addresses only use the low 32 bits
+type uint128 struct {
+	hi, lo uint64
+}
+
+func (a uint128) compare(b uint128) int {
+	if c := cmp.Compare(a.hi, b.hi); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.lo, b.lo)
+}
+
+func (a uint128) add(n uint64) uint128 {
+	lo := a.lo + n
+	hi := a.hi
+	if lo < a.lo {
+		hi++
+	}
+	return uint128{hi, lo}
+}
+
+func (a uint128) sub(b uint128) uint128 {
+	lo := a.lo - b.lo
+	hi := a.hi - b.hi
+	if a.lo < b.lo {
+		hi--
+	}
+	return uint128{hi, lo}
+}
+
+// bit returns bit i counting from the most significant one
+func (a uint128) bit(i int) uint64 {
+	if i < 64 {
+		return a.hi >> (63 - i) & 1
+	}
+	return a.lo >> (127 - i) & 1
+}
+
+// setBit sets bit i counting from the most significant one to value
+func (a uint128) setBit(i int, value uint64) uint128 {
+	if i < 64 {
+		a.hi = a.hi&^(1<<(63-i)) | value<<(63-i)
+	} else {
+		a.lo = a.lo&^(1<<(127-i)) | value<<(127-i)
+	}
+	return a
+}
+
+// keepTop clears everything but the first n bits
+func (a uint128) keepTop(n int) uint128 {
+	switch {
+	case n == 0:
+		return uint128{}
+	case n < 64:
+		return uint128{a.hi &^ (^uint64(0) >> n), 0}
+	case n == 64:
+		return uint128{a.hi, 0}
+	default:
+		return uint128{a.hi, a.lo &^ (^uint64(0) >> (n - 64))}
+	}
+}
+
+func fromAddr(addr netip.Addr) uint128 {
+	if addr.Is4() {
+		a4 := addr.As4()
+		return uint128{0, uint64(binary.BigEndian.Uint32(a4[:]))}
+	}
+
+	a16 := addr.As16()
+	return uint128{binary.BigEndian.Uint64(a16[:8]), binary.BigEndian.Uint64(a16[8:])}
+}
+
+func toAddr(value uint128, is4 bool) netip.Addr {
+	if is4 {
+		var a4 [4]byte
+		binary.BigEndian.PutUint32(a4[:], uint32(value.lo))
+		return netip.AddrFrom4(a4)
+	}
+
+	var a16 [16]byte
+	binary.BigEndian.PutUint64(a16[:8], value.hi)
+	binary.BigEndian.PutUint64(a16[8:], value.lo)
+	return netip.AddrFrom16(a16)
+}
+
+// span is an inclusive range of addresses from the same family
+type span struct {
+	first, last uint128
+}
+
+func prefixSpan(prefix netip.Prefix) span {
+	prefix = prefix.Masked()
+	first := fromAddr(prefix.Addr())
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+
+	var mask uint128
+	switch {
+	case hostBits >= 128:
+		mask = uint128{^uint64(0), ^uint64(0)}
+	case hostBits >= 64:
+		mask = uint128{1<<(hostBits-64) - 1, ^uint64(0)}
+	default:
+		mask = uint128{0, 1<<hostBits - 1}
+	}
+
+	return span{first, uint128{first.hi | mask.hi, first.lo | mask.lo}}
+}
+
+// mergeSpans sorts spans and joins the ones that overlap or touch
+func mergeSpans(spans []span) []span {
+	slices.SortFunc(spans, func(a, b span) int {
+		return a.first.compare(b.first)
+	})
+
+	merged := spans[:0]
+	for _, s := range spans {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+
+			if s.first.compare(last.last) <= 0 || s.first.sub(uint128{0, 1}) == last.last {
+				if s.last.compare(last.last) > 0 {
+					last.last = s.last
+				}
+				continue
+			}
+		}
+
+		merged = append(merged, s)
+	}
+
+	return merged
+}
+
+// subtractSpans removes every address in exclude from include, both have to be merged
+func subtractSpans(include, exclude []span) []span {
+	var result []span
+	j := 0
+
+	for _, inc := range include {
+		// Excludes that end before this include can't affect any of the following ones either
+		for j < len(exclude) && exclude[j].last.compare(inc.first) < 0 {
+			j++
+		}
+
+		current := inc.first
+		done := false
+
+		for k := j; k < len(exclude) && exclude[k].first.compare(inc.last) <= 0; k++ {
+			ex := exclude[k]
+
+			if ex.first.compare(current) > 0 {
+				result = append(result, span{current, ex.first.sub(uint128{0, 1})})
+			}
+
+			if ex.last.compare(inc.last) >= 0 {
+				done = true
+				break
+			}
+
+			if ex.last.compare(current) >= 0 {
+				current = ex.last.add(1)
+			}
+		}
+
+		if !done {
+			result = append(result, span{current, inc.last})
+		}
+	}
+
+	return result
+}
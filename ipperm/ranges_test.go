@@ -0,0 +1,58 @@
+package ipperm
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func v4(s string) uint128 {
+	return fromAddr(netip.MustParseAddr(s))
+}
+
+func TestPrefixSpan(t *testing.T) {
+	tests := []struct {
+		prefix      string
+		first, last uint128
+	}{
+		{"10.1.2.3/24", v4("10.1.2.0"), v4("10.1.2.255")},
+		{"0.0.0.0/0", v4("0.0.0.0"), v4("255.255.255.255")},
+		{"10.0.0.1/32", v4("10.0.0.1"), v4("10.0.0.1")},
+		{"::/0", uint128{}, uint128{^uint64(0), ^uint64(0)}},
+		{"2001:db8::/64", uint128{0x20010db800000000, 0}, uint128{0x20010db800000000, ^uint64(0)}},
+		{"2001:db8::/32", uint128{0x20010db800000000, 0}, uint128{0x20010db8ffffffff, ^uint64(0)}},
+	}
+
+	for _, test := range tests {
+		s := prefixSpan(netip.MustParsePrefix(test.prefix))
+		if s.first != test.first || s.last != test.last {
+			t.Errorf("Expected %s to span %v to %v, got %v to %v", test.prefix, test.first, test.last, s.first, s.last)
+		}
+	}
+}
+
+func TestMergeAndSubtractSpans(t *testing.T) {
+	n := func(first, last uint64) span {
+		return span{uint128{0, first}, uint128{0, last}}
+	}
+
+	merged := mergeSpans([]span{n(20, 30), n(0, 5), n(6, 10), n(0, 2), n(25, 40), n(50, 60)})
+	if !slices.Equal(merged, []span{n(0, 10), n(20, 40), n(50, 60)}) {
+		t.Fatalf("Unexpected merge %v", merged)
+	}
+
+	exclude := mergeSpans([]span{n(0, 1), n(8, 22), n(30, 30), n(55, 70)})
+	result := subtractSpans(merged, exclude)
+	if !slices.Equal(result, []span{n(2, 7), n(23, 29), n(31, 40), n(50, 54)}) {
+		t.Fatalf("Unexpected subtraction %v", result)
+	}
+
+	if result := subtractSpans([]span{n(5, 10)}, []span{n(0, 20)}); len(result) != 0 {
+		t.Errorf("Expected nothing to be left, got %v", result)
+	}
+
+	all := span{uint128{}, uint128{^uint64(0), ^uint64(0)}}
+	if result := mergeSpans([]span{all, n(0, 5)}); !slices.Equal(result, []span{all}) {
+		t.Errorf("Expected the whole space to absorb everything, got %v", result)
+	}
+}
@@ -0,0 +1,70 @@
+package feistel
+
+import "iter"
+
+// All returns an iterator over every index in the sequence paired with the value it maps to
+func (n *Network) All() iter.Seq2[uint64, uint64] {
+	return n.Range(0, n.maxValue)
+}
+
+// Values returns an iterator over the mapped values of the sequence, in other words the permutation itself
+func (n *Network) Values() iter.Seq[uint64] {
+	all := n.All()
+
+	return func(yield func(uint64) bool) {
+		for _, value := range all {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the indices from start to end (inclusive) paired with the values they map to.
+// If the network was created WithEpochs the range can cross max value and will continue into the following epochs,
+// otherwise end is clamped to max value. If start is greater than end nothing is yielded.
+// An iterator has no way to report an error, so with WithMaxWalks or WithConstantTime the iterators keep walking
+// past the limit like a network without it, every value is the same as Map returns when it doesn't fail.
+func (n *Network) Range(start, end uint64) iter.Seq2[uint64, uint64] {
+	return n.iterate(start, end, false)
+}
+
+// InvertAll returns an iterator over every value in the sequence paired with the index that maps to it
+func (n *Network) InvertAll() iter.Seq2[uint64, uint64] {
+	return n.InvertRange(0, n.maxValue)
+}
+
+// InvertRange is the inverse of Range, it yields each value from start to end (inclusive) paired with the result
+// of InvertMap on that value
+func (n *Network) InvertRange(start, end uint64) iter.Seq2[uint64, uint64] {
+	return n.iterate(start, end, true)
+}
+
+func (n *Network) iterate(start, end uint64, invert bool) iter.Seq2[uint64, uint64] {
+	if n.unbounded != nil {
+		return n.unbounded.iterate(start, end, invert)
+	}
+
+	if !n.epochs && end > n.maxValue {
+		end = n.maxValue
+	}
+
+	return func(yield func(uint64, uint64) bool) {
+		if start > end {
+			return
+		}
+
+		for i := start; ; i++ {
+			// encode can only fail when the index is out of range or on the walk limit,
+			// the range was clamped above and this network has no walk limit
+			value, err := n.encode(i, 0, invert)
+			if err != nil {
+				panic(err)
+			}
+
+			if !yield(i, value) || i == end {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package feistel
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAllMatchesMap(t *testing.T) {
+	for _, maxValue := range []uint64{0, 1, 13, 1000} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := uint64(0)
+			seen := make(map[uint64]struct{}, maxValue+1)
+
+			for index, value := range net.All() {
+				if index != expected {
+					t.Fatalf("Expected index %d but got %d", expected, index)
+				}
+
+				mapped, err := net.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mapped != value {
+					t.Fatalf("All yielded %d for %d but Map returned %d", value, index, mapped)
+				}
+
+				seen[value] = struct{}{}
+				expected++
+			}
+
+			if expected != maxValue+1 || uint64(len(seen)) != maxValue+1 {
+				t.Errorf("Expected %d unique values, got %d from %d indices", maxValue+1, len(seen), expected)
+			}
+		})
+	}
+}
+
+func TestInvertAllMatchesInvertMap(t *testing.T) {
+	net, err := NewNetwork(101, 7, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for value, index := range net.InvertAll() {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != value {
+			t.Fatalf("InvertAll yielded %d for %d but Map(%d) is %d", index, value, index, mapped)
+		}
+	}
+}
+
+func TestValuesIsPermutation(t *testing.T) {
+	net, err := NewNetwork(64, 3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[uint64]struct{}, 65)
+	for value := range net.Values() {
+		if _, ok := seen[value]; ok {
+			t.Fatalf("Value %d yielded twice", value)
+		}
+		seen[value] = struct{}{}
+	}
+
+	if len(seen) != 65 {
+		t.Errorf("Expected 65 values, got %d", len(seen))
+	}
+}
+
+func TestRangeClampsWithoutEpochs(t *testing.T) {
+	net, err := NewNetwork(9, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for index := range net.Range(5, 100) {
+		if index > 9 {
+			t.Fatalf("Range yielded %d which is above max value", index)
+		}
+		count++
+	}
+
+	if count != 5 {
+		t.Errorf("Expected 5 values, got %d", count)
+	}
+}
+
+func TestRangeCrossesEpochs(t *testing.T) {
+	net, err := NewNetwork(9, 1, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := uint64(0)
+	for index, value := range net.Range(5, 34) {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != value {
+			t.Fatalf("Range yielded %d for %d but Map returned %d", value, index, mapped)
+		}
+		count++
+	}
+
+	if count != 30 {
+		t.Errorf("Expected 30 values, got %d", count)
+	}
+}
+
+func TestRangeStopsOnBreak(t *testing.T) {
+	net, err := NewNetwork(^uint64(0), 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for range net.Range(^uint64(0)-5, ^uint64(0)) {
+		count++
+	}
+
+	if count != 6 {
+		t.Errorf("Expected 6 values at the end of the range, got %d", count)
+	}
+
+	count = 0
+	for range net.All() {
+		count++
+		if count == 10 {
+			break
+		}
+	}
+
+	if count != 10 {
+		t.Errorf("Expected to stop after 10 values, got %d", count)
+	}
+}
+
+func TestRangeEmpty(t *testing.T) {
+	net, err := NewNetwork(9, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index := range net.Range(6, 5) {
+		t.Fatalf("Range with start after end yielded %d", index)
+	}
+}
@@ -0,0 +1,72 @@
+package feistel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidKeySize is returned when the key provided for an AES based network isn't 16, 24 or 32 bytes long
+var ErrInvalidKeySize = errors.New("feistel: key must be 16, 24 or 32 bytes")
+
+// NewKeyedNetwork creates a new Feistel Network where the round keys are derived from key and every round
+// runs AES instead of SplitMix64. The permutation can't be predicted without the key, so it's the mode to use when
+// you are hiding something like customer IDs. It's considerably slower than NewNetwork.
+// key has to be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+// maxValue, rounds and opts behave the same as in NewNetwork, any WithRoundFunc option is ignored.
+func NewKeyedNetwork(maxValue uint64, key []byte, rounds uint8, opts ...Option) (*Network, error) {
+	roundFunc, err := NewAES(key)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := NewNetwork(maxValue, 0, rounds, append(opts[:len(opts):len(opts)], WithRoundFunc(roundFunc))...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range network.seeds {
+		network.seeds[i] = roundFunc.roundKey(uint64(i))
+	}
+
+	return network, nil
+}
+
+// AES is a RoundFunc that encrypts the round key and the half value as a single block with AES
+// and reduces the first 8 bytes of the result to the radix
+type AES struct {
+	block cipher.Block
+}
+
+// NewAES creates an AES RoundFunc, key has to be 16, 24 or 32 bytes
+func NewAES(key []byte) (*AES, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w, size: %d", ErrInvalidKeySize, len(key))
+	}
+
+	return &AES{block: block}, nil
+}
+
+// Round implements RoundFunc
+func (r *AES) Round(key, value, radix uint64) uint64 {
+	return r.encrypt(key, value) % radix
+}
+
+// roundKey derives the key for a round, half values never come close to all ones so the blocks encrypted
+// here can't collide with the ones encrypted in Round
+func (r *AES) roundKey(round uint64) uint64 {
+	return r.encrypt(round, ^uint64(0))
+}
+
+func (r *AES) encrypt(left, right uint64) uint64 {
+	var buf [aes.BlockSize]byte
+
+	binary.BigEndian.PutUint64(buf[:8], left)
+	binary.BigEndian.PutUint64(buf[8:], right)
+	r.block.Encrypt(buf[:], buf[:])
+
+	return binary.BigEndian.Uint64(buf[:8])
+}
@@ -0,0 +1,130 @@
+package feistel
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	testKey128 = []byte("0123456789abcdef")
+	testKey256 = []byte("0123456789abcdef0123456789abcdef")
+)
+
+func mustAES(key []byte) *AES {
+	roundFunc, err := NewAES(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return roundFunc
+}
+
+func TestKeyedNetworkKnownAnswers(t *testing.T) {
+	indices := []uint64{0, 1, 2, 42, 998}
+	tests := []struct {
+		key      []byte
+		maxValue uint64
+		expected []uint64
+	}{
+		{testKey128, 999, []uint64{0x175, 0x20c, 0x3c9, 0x197, 0x3df}},
+		{testKey128, 1 << 40, []uint64{0x79a718d183, 0xba96852ee5, 0x3872dbe993, 0x794a1a65b6, 0x938c507e3c}},
+		{testKey128, ^uint64(0), []uint64{0x6332154dd707e88, 0x686012656082f11d, 0x47a607d0d080b7eb, 0x3fa1887de793adeb, 0xae489e0972f588b0}},
+		{testKey256, 999, []uint64{0x1a6, 0x244, 0xe5, 0x42, 0x36d}},
+		{testKey256, 1 << 40, []uint64{0xacbd276e0, 0x1aa320d555, 0x8a336d642f, 0xc4c7a2965e, 0x1582bc3947}},
+		{testKey256, ^uint64(0), []uint64{0x8fd761a2b2c6cbdc, 0x776804e9cb1fa578, 0x35a20a79dd5734f8, 0x70a5bef2b827045b, 0x41b4fe78e06f007b}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("key size %d, maxValue %d", len(test.key), test.maxValue), func(t *testing.T) {
+			net, err := NewKeyedNetwork(test.maxValue, test.key, 8, WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, index := range indices {
+				mapped, err := net.Map(index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if mapped != test.expected[i] {
+					t.Errorf("Expected %d to map to %#x but got %#x", index, test.expected[i], mapped)
+				}
+
+				inverted, err := net.InvertMap(mapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if inverted != index {
+					t.Errorf("Inverted %#x to %d but expected %d", mapped, inverted, index)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyedNetworkEpochs(t *testing.T) {
+	net, err := NewKeyedNetwork(999, testKey128, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, expected := range map[uint64]uint64{1000: 1027, 5432: 5926} {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != expected {
+			t.Errorf("Expected %d to map to %d but got %d", index, expected, mapped)
+		}
+	}
+}
+
+func TestKeyedNetworkIgnoresRoundFunc(t *testing.T) {
+	net, err := NewKeyedNetwork(999, testKey128, 8, WithRoundFunc(SplitMix64{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := net.roundFunc.(*AES); !ok {
+		t.Errorf("Expected the AES round func, got %T", net.roundFunc)
+	}
+}
+
+func TestAESRoundMatchesBlockCipher(t *testing.T) {
+	block, err := aes.NewCipher(testKey256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roundFunc := mustAES(testKey256)
+
+	for value := range uint64(100) {
+		var buf [aes.BlockSize]byte
+		binary.BigEndian.PutUint64(buf[:8], 7)
+		binary.BigEndian.PutUint64(buf[8:], value)
+		block.Encrypt(buf[:], buf[:])
+
+		expected := binary.BigEndian.Uint64(buf[:8]) % 1000
+		if result := roundFunc.Round(7, value, 1000); result != expected {
+			t.Fatalf("Expected round of %d to be %d but got %d", value, expected, result)
+		}
+	}
+}
+
+func TestKeyedNetworkInvalidKey(t *testing.T) {
+	for _, size := range []int{0, 8, 15, 33} {
+		if _, err := NewKeyedNetwork(999, make([]byte, size), 8); !errors.Is(err, ErrInvalidKeySize) {
+			t.Errorf("Expected ErrInvalidKeySize for a %d byte key, got %v", size, err)
+		}
+	}
+
+	if _, err := NewKeyedNetwork(999, testKey128, 0); !errors.Is(err, ErrRoundsMustBeSet) {
+		t.Errorf("Expected ErrRoundsMustBeSet, got %v", err)
+	}
+}
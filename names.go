@@ -0,0 +1,189 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidWordList is returned when a word list is empty, repeats a word, has a word with a dash in it,
+// or when all the lists together have more names than fit in a uint64
+var ErrInvalidWordList = errors.New("feistel: invalid word list")
+
+// ErrInvalidName is returned when a name doesn't match the word lists and suffixes of a NameGenerator
+var ErrInvalidName = errors.New("feistel: invalid name")
+
+// DefaultAdjectives is the first word list of NewDefaultNameGenerator
+var DefaultAdjectives = []string{
+	"able", "bold", "brave", "bright", "calm", "clever", "cool", "crisp",
+	"curious", "daring", "eager", "early", "fair", "fancy", "fast", "fierce",
+	"fond", "free", "fresh", "gentle", "glad", "golden", "grand", "happy",
+	"hardy", "honest", "humble", "jolly", "keen", "kind", "lively", "loyal",
+	"lucky", "merry", "mighty", "modest", "neat", "nimble", "noble", "patient",
+	"plucky", "polite", "proud", "quick", "quiet", "rapid", "ready", "regal",
+	"shiny", "silent", "smart", "snowy", "solid", "steady", "sunny", "swift",
+	"tidy", "tough", "trusty", "vivid", "warm", "wild", "wise", "witty",
+}
+
+// DefaultNouns is the second word list of NewDefaultNameGenerator
+var DefaultNouns = []string{
+	"badger", "bear", "beaver", "bison", "cobra", "condor", "crane", "deer",
+	"dolphin", "eagle", "falcon", "ferret", "finch", "fox", "gecko", "heron",
+	"hawk", "ibis", "jaguar", "koala", "lemur", "leopard", "lion", "lynx",
+	"magpie", "marten", "moose", "newt", "ocelot", "orca", "otter", "owl",
+	"panda", "panther", "parrot", "pelican", "penguin", "puma", "quail", "rabbit",
+	"raven", "robin", "salmon", "seal", "shark", "sparrow", "stork", "swan",
+	"tapir", "tiger", "toucan", "trout", "turtle", "viper", "walrus", "weasel",
+	"whale", "wolf", "wombat", "wren", "yak", "zebra", "bobcat", "coyote",
+}
+
+// NameGenerator maps counters to names like brave-tiger-042 and names back to counters.
+// A name is one word from each word list followed by a number for each suffix, joined by dashes.
+// They're read as digits of a mixed radix number and mapped by a Network over all the possible names,
+// so names never repeat until every name has been used.
+type NameGenerator struct {
+	network  *Network
+	lists    [][]string
+	lookups  []map[string]uint64
+	suffixes []int
+	radices  []uint64
+	size     uint64
+}
+
+// NewNameGenerator creates a NameGenerator, suffixDigits has the width of each numeric suffix so 3 gives 000 to 999.
+// seed, rounds and opts are passed to NewNetwork, there's nothing past the last name so epochs have no effect.
+func NewNameGenerator(lists [][]string, suffixDigits []int, seed uint64, rounds uint8, opts ...Option) (*NameGenerator, error) {
+	g := &NameGenerator{
+		lists:    lists,
+		lookups:  make([]map[string]uint64, len(lists)),
+		suffixes: suffixDigits,
+		radices:  make([]uint64, 0, len(lists)+len(suffixDigits)),
+		size:     1,
+	}
+
+	for i, list := range lists {
+		if len(list) == 0 {
+			return nil, fmt.Errorf("%w, list %d is empty", ErrInvalidWordList, i)
+		}
+
+		g.lookups[i] = make(map[string]uint64, len(list))
+		for j, word := range list {
+			if word == "" || strings.Contains(word, "-") {
+				return nil, fmt.Errorf("%w, %q can't be empty or contain a dash", ErrInvalidWordList, word)
+			}
+
+			if _, ok := g.lookups[i][word]; ok {
+				return nil, fmt.Errorf("%w, %q is repeated in list %d", ErrInvalidWordList, word, i)
+			}
+			g.lookups[i][word] = uint64(j)
+		}
+
+		g.radices = append(g.radices, uint64(len(list)))
+	}
+
+	for _, digits := range suffixDigits {
+		if digits < 1 || digits > 19 {
+			return nil, fmt.Errorf("%w, suffixes need between 1 and 19 digits, got %d", ErrInvalidLength, digits)
+		}
+
+		radix := uint64(1)
+		for range digits {
+			radix *= 10
+		}
+		g.radices = append(g.radices, radix)
+	}
+
+	if len(g.radices) == 0 {
+		return nil, fmt.Errorf("%w, there has to be at least one word list or suffix", ErrInvalidWordList)
+	}
+
+	for _, radix := range g.radices {
+		hi, lo := bits.Mul64(g.size, radix)
+		if hi != 0 {
+			return nil, fmt.Errorf("%w, the number of names doesn't fit in a uint64", ErrInvalidWordList)
+		}
+		g.size = lo
+	}
+
+	network, err := NewNetwork(g.size-1, seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	g.network = network
+	return g, nil
+}
+
+// NewDefaultNameGenerator creates a NameGenerator with DefaultAdjectives, DefaultNouns and a 3 digit suffix
+func NewDefaultNameGenerator(seed uint64, rounds uint8, opts ...Option) (*NameGenerator, error) {
+	return NewNameGenerator([][]string{DefaultAdjectives, DefaultNouns}, []int{3}, seed, rounds, opts...)
+}
+
+// Size returns the number of unique names, counters go from 0 to Size() - 1
+func (g *NameGenerator) Size() uint64 {
+	return g.size
+}
+
+// Name maps counter to a name
+func (g *NameGenerator) Name(counter uint64) (string, error) {
+	// With WithEpochs the network would wrap the counter and Counter couldn't give it back
+	if counter >= g.size {
+		return "", fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, counter, g.size-1)
+	}
+
+	value, err := g.network.Map(counter)
+	if err != nil {
+		return "", err
+	}
+
+	digits := make([]uint64, len(g.radices))
+	for i := len(g.radices) - 1; i >= 0; i-- {
+		digits[i] = value % g.radices[i]
+		value /= g.radices[i]
+	}
+
+	parts := make([]string, len(digits))
+	for i, digit := range digits {
+		if i < len(g.lists) {
+			parts[i] = g.lists[i][digit]
+		} else {
+			parts[i] = fmt.Sprintf("%0*d", g.suffixes[i-len(g.lists)], digit)
+		}
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+// Counter performs an inversion of Name
+func (g *NameGenerator) Counter(name string) (uint64, error) {
+	parts := strings.Split(name, "-")
+	if len(parts) != len(g.radices) {
+		return 0, fmt.Errorf("%w, %q should have %d parts", ErrInvalidName, name, len(g.radices))
+	}
+
+	var value uint64
+	for i, part := range parts {
+		var digit uint64
+
+		if i < len(g.lists) {
+			var ok bool
+			if digit, ok = g.lookups[i][part]; !ok {
+				return 0, fmt.Errorf("%w, %q isn't in word list %d", ErrInvalidName, part, i)
+			}
+		} else {
+			width := g.suffixes[i-len(g.lists)]
+
+			parsed, err := strconv.ParseUint(part, 10, 64)
+			if err != nil || len(part) != width {
+				return 0, fmt.Errorf("%w, %q should be a %d digit number", ErrInvalidName, part, width)
+			}
+			digit = parsed
+		}
+
+		value = value*g.radices[i] + digit
+	}
+
+	return g.network.InvertMap(value)
+}
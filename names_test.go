@@ -0,0 +1,127 @@
+package feistel
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestDefaultNameGenerator(t *testing.T) {
+	g, err := NewDefaultNameGenerator(42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Size() != 64*64*1000 {
+		t.Fatalf("Expected %d names, got %d", 64*64*1000, g.Size())
+	}
+
+	pattern := regexp.MustCompile(`^[a-z]+-[a-z]+-[0-9]{3}$`)
+	seen := make(map[string]struct{})
+
+	for counter := range uint64(50000) {
+		name, err := g.Name(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !pattern.MatchString(name) {
+			t.Fatalf("Name %q for %d doesn't look like adjective-noun-number", name, counter)
+		}
+
+		if _, ok := seen[name]; ok {
+			t.Fatalf("Name %q was generated twice", name)
+		}
+		seen[name] = struct{}{}
+
+		decoded, err := g.Counter(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded != counter {
+			t.Fatalf("Mapped %d to %q and inversion produced %d", counter, name, decoded)
+		}
+	}
+}
+
+func TestNameGeneratorExhaustsSpace(t *testing.T) {
+	lists := [][]string{{"red", "green", "blue"}, {"cat", "dog"}}
+	g, err := NewNameGenerator(lists, []int{1}, 7, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]struct{})
+	for counter := range g.Size() {
+		name, err := g.Name(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[name] = struct{}{}
+	}
+
+	if len(seen) != 60 {
+		t.Errorf("Expected all 60 names to be generated, got %d", len(seen))
+	}
+
+	if _, err := g.Name(60); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue once the names run out, got %v", err)
+	}
+}
+
+func TestNameGeneratorInvalidNames(t *testing.T) {
+	g, err := NewDefaultNameGenerator(42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"brave-tiger", "brave-tiger-42", "brave-tiger-0042", "brave-unicorn-042", "tiger-brave-042", "brave-tiger-04x", "brave-tiger-042-1"} {
+		if _, err := g.Counter(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Expected ErrInvalidName for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestNameGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		lists    [][]string
+		suffixes []int
+		expected error
+	}{
+		{[][]string{{}}, nil, ErrInvalidWordList},
+		{[][]string{{"a", "a"}}, nil, ErrInvalidWordList},
+		{[][]string{{"a-b"}}, nil, ErrInvalidWordList},
+		{nil, nil, ErrInvalidWordList},
+		{nil, []int{0}, ErrInvalidLength},
+		{nil, []int{19, 1}, ErrInvalidWordList},
+	}
+
+	for _, test := range tests {
+		if _, err := NewNameGenerator(test.lists, test.suffixes, 1, 8); !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for %v and %v, got %v", test.expected, test.lists, test.suffixes, err)
+		}
+	}
+}
+
+func TestNameGeneratorEpochs(t *testing.T) {
+	g, err := NewNameGenerator([][]string{{"a", "b"}, {"c", "d", "e"}}, nil, 1, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for counter := range g.Size() {
+		name, err := g.Name(counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if back, err := g.Counter(name); err != nil || back != counter {
+			t.Errorf("Named %d %q and Counter returned %d, %v", counter, name, back, err)
+		}
+	}
+
+	if _, err := g.Name(g.Size()); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue past the last name, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIndexLessThanMinValue is returned when your index is less than the minimum value of a RangeNetwork
+var ErrIndexLessThanMinValue = errors.New("feistel: index cannot be less than min value")
+
+// ErrMinGreaterThanMax is returned when a RangeNetwork is created with a min value above its max value
+var ErrMinGreaterThanMax = errors.New("feistel: min value cannot be greater than max value")
+
+// Integer is any integer type a RangeNetwork can permute
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// RangeNetwork permutes the integers from a min value to a max value (inclusive), both can be negative.
+// Values are shifted down by min value and mapped by a Network over the range from 0 to max value - min value.
+type RangeNetwork[T Integer] struct {
+	network  *Network
+	minValue T
+	maxValue T
+}
+
+var _ Mapper = (*RangeNetwork[uint64])(nil)
+
+// NewRangeNetwork creates a RangeNetwork for the range from minValue to maxValue,
+// seed, rounds and opts are passed to NewNetwork. With WithEpochs values above maxValue continue
+// into the next epochs as long as the result still fits in T.
+func NewRangeNetwork[T Integer](minValue, maxValue T, seed uint64, rounds uint8, opts ...Option) (*RangeNetwork[T], error) {
+	if minValue > maxValue {
+		return nil, fmt.Errorf("%w, minValue: %v, maxValue: %v", ErrMinGreaterThanMax, minValue, maxValue)
+	}
+
+	// The subtraction wraps around for signed types but the difference always fits in a uint64
+	network, err := NewNetwork(uint64(maxValue)-uint64(minValue), seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeNetwork[T]{
+		network:  network,
+		minValue: minValue,
+		maxValue: maxValue,
+	}, nil
+}
+
+// Network returns the underlying Network over the range from 0 to max value - min value
+func (r *RangeNetwork[T]) Network() *Network {
+	return r.network
+}
+
+// Map takes a value in the range and maps it to another value in the same range
+func (r *RangeNetwork[T]) Map(value T) (T, error) {
+	return r.encode(value, r.network.Map)
+}
+
+// InvertMap performs an inversion of Map
+func (r *RangeNetwork[T]) InvertMap(value T) (T, error) {
+	return r.encode(value, r.network.InvertMap)
+}
+
+func (r *RangeNetwork[T]) encode(value T, fn func(uint64) (uint64, error)) (T, error) {
+	if value < r.minValue {
+		return 0, fmt.Errorf("%w, index: %v, minValue: %v", ErrIndexLessThanMinValue, value, r.minValue)
+	}
+
+	if value > r.maxValue && !r.network.epochs {
+		return 0, fmt.Errorf("%w, index: %v, maxValue: %v", ErrIndexGreatThanMaxValue, value, r.maxValue)
+	}
+
+	offset := uint64(value) - uint64(r.minValue)
+
+	mapped, err := fn(offset)
+	if err != nil {
+		return 0, err
+	}
+
+	result := T(uint64(r.minValue) + mapped)
+
+	// Only possible with epochs, the epoch the value is in runs past the largest value of T
+	if result < r.minValue || uint64(result)-uint64(r.minValue) != mapped {
+		return 0, fmt.Errorf("%w, index: %v maps outside of the type's range", ErrIndexGreatThanMaxValue, value)
+	}
+
+	return result, nil
+}
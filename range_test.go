@@ -0,0 +1,178 @@
+package feistel
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func testRangePermutation[T Integer](t *testing.T, net *RangeNetwork[T], minValue, maxValue T) {
+	t.Helper()
+
+	seen := make(map[T]struct{}, int(maxValue)-int(minValue)+1)
+
+	for value := minValue; ; value++ {
+		mapped, err := net.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped < minValue || mapped > maxValue {
+			t.Fatalf("Mapped %v to %v which is outside of [%v, %v]", value, mapped, minValue, maxValue)
+		}
+
+		if _, ok := seen[mapped]; ok {
+			t.Fatalf("%v was mapped twice", mapped)
+		}
+		seen[mapped] = struct{}{}
+
+		inverted, err := net.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != value {
+			t.Fatalf("Mapped %v to %v and inversion produced %v", value, mapped, inverted)
+		}
+
+		if value == maxValue {
+			break
+		}
+	}
+}
+
+func TestRangeNetworkUnsigned(t *testing.T) {
+	net, err := NewRangeNetwork[uint64](1000, 9999, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRangePermutation(t, net, 1000, 9999)
+}
+
+func TestRangeNetworkSigned(t *testing.T) {
+	net, err := NewRangeNetwork[int64](-500, 500, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRangePermutation(t, net, -500, 500)
+
+	negative, err := NewRangeNetwork(-1000, -10, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRangePermutation(t, negative, -1000, -10)
+}
+
+func TestRangeNetworkFullTypeRange(t *testing.T) {
+	small, err := NewRangeNetwork[int8](math.MinInt8, math.MaxInt8, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRangePermutation(t, small, math.MinInt8, math.MaxInt8)
+
+	full, err := NewRangeNetwork[int64](math.MinInt64, math.MaxInt64, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []int64{math.MinInt64, -1, 0, 1, math.MaxInt64} {
+		mapped, err := full.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inverted, err := full.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != value {
+			t.Errorf("Mapped %d to %d and inversion produced %d", value, mapped, inverted)
+		}
+	}
+}
+
+func TestRangeNetworkMatchesNetwork(t *testing.T) {
+	ranged, err := NewRangeNetwork[int](-50, 50, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	net, err := NewNetwork(100, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, value := range net.All() {
+		mapped, err := ranged.Map(int(index) - 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != int(value)-50 {
+			t.Fatalf("Expected %d to map to %d but got %d", int(index)-50, int(value)-50, mapped)
+		}
+	}
+}
+
+func TestRangeNetworkErrors(t *testing.T) {
+	if _, err := NewRangeNetwork(10, 5, 42, 8); !errors.Is(err, ErrMinGreaterThanMax) {
+		t.Errorf("Expected ErrMinGreaterThanMax, got %v", err)
+	}
+
+	if _, err := NewRangeNetwork(5, 10, 42, 0); !errors.Is(err, ErrRoundsMustBeSet) {
+		t.Errorf("Expected ErrRoundsMustBeSet, got %v", err)
+	}
+
+	net, err := NewRangeNetwork[int64](-500, 500, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.Map(-501); !errors.Is(err, ErrIndexLessThanMinValue) {
+		t.Errorf("Expected ErrIndexLessThanMinValue, got %v", err)
+	}
+
+	if _, err := net.InvertMap(501); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
+
+func TestRangeNetworkEpochs(t *testing.T) {
+	net, err := NewRangeNetwork[int8](-100, -1, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second epoch covers 0 to 99
+	for value := int8(0); value < 100; value++ {
+		mapped, err := net.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped < 0 || mapped >= 100 {
+			t.Fatalf("Mapped %d to %d which is outside of its epoch", value, mapped)
+		}
+
+		if inverted, err := net.InvertMap(mapped); err != nil || inverted != value {
+			t.Fatalf("Mapped %d to %d and inversion produced %d, %v", value, mapped, inverted, err)
+		}
+	}
+
+	// The third epoch covers 100 to 199 which doesn't fit in an int8
+	failures := 0
+	for value := int8(100); value < math.MaxInt8; value++ {
+		if _, err := net.Map(value); errors.Is(err, ErrIndexGreatThanMaxValue) {
+			failures++
+		}
+	}
+
+	if failures == 0 {
+		t.Error("Expected some values of the last epoch to map outside of int8")
+	}
+}
@@ -0,0 +1,109 @@
+package feistel
+
+import "math/bits"
+
+// RoundFunc is the function applied to one half of the value in each round of the network.
+// key is the round key (the per round seed mixed with the epoch), value is the half being hashed
+// and radix is the size of the other half. Round must return a value in the range [0, radix)
+// and it must be deterministic, the same inputs always have to produce the same output or the network
+// won't be invertible.
+type RoundFunc interface {
+	Round(key, value, radix uint64) uint64
+}
+
+// WithRoundFunc is an option that replaces the round function used by the network, the default is SplitMix64
+func WithRoundFunc(roundFunc RoundFunc) Option {
+	return func(m *Network) {
+		m.roundFunc = roundFunc
+	}
+}
+
+// SplitMix64 is the default RoundFunc, it's very fast and distributes well but it's trivial to reverse
+// so don't rely on it if somebody might try to predict the permutation
+type SplitMix64 struct{}
+
+// Round implements RoundFunc
+func (SplitMix64) Round(key, value, radix uint64) uint64 {
+	return splitmix64(value^key) % radix
+}
+
+// SipHash24 is a RoundFunc using SipHash-2-4 keyed with the round key, it's several times slower than
+// SplitMix64 but it was designed to stand up to inputs chosen by an adversary
+type SipHash24 struct{}
+
+// Round implements RoundFunc
+func (SipHash24) Round(key, value, radix uint64) uint64 {
+	return sipHash24(key, key^0x5851f42d4c957f2d, value) % radix
+}
+
+// XXH64 is a RoundFunc based on the xxHash64 construction for a single 8 byte input seeded with the round key,
+// it sits between SplitMix64 and SipHash24 in both speed and mixing quality
+type XXH64 struct{}
+
+const (
+	xxhPrime1 uint64 = 0x9e3779b185ebca87
+	xxhPrime2 uint64 = 0xc2b2ae3d27d4eb4f
+	xxhPrime3 uint64 = 0x165667b19e3779f9
+	xxhPrime4 uint64 = 0x85ebca77c2b2ae63
+	xxhPrime5 uint64 = 0x27d4eb2f165667c5
+)
+
+// Round implements RoundFunc
+func (XXH64) Round(key, value, radix uint64) uint64 {
+	h := key + xxhPrime5 + 8
+	h ^= bits.RotateLeft64(value*xxhPrime2, 31) * xxhPrime1
+	h = bits.RotateLeft64(h, 27)*xxhPrime1 + xxhPrime4
+
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+
+	return h % radix
+}
+
+// sipHash24 computes SipHash-2-4 of the 8 byte little endian encoding of m
+func sipHash24(k0, k1, m uint64) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	v3 ^= m
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= m
+
+	// The final block only holds the message length
+	last := uint64(8) << 56
+	v3 ^= last
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= last
+
+	v2 ^= 0xff
+	for range 4 {
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	}
+
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+func sipRound(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13)
+	v1 ^= v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16)
+	v3 ^= v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21)
+	v3 ^= v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17)
+	v1 ^= v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
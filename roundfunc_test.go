@@ -0,0 +1,71 @@
+package feistel
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSipHash24ReferenceVector(t *testing.T) {
+	// Key 00..0f and message 00..07 from the SipHash paper test vectors
+	result := sipHash24(0x0706050403020100, 0x0f0e0d0c0b0a0908, 0x0706050403020100)
+
+	if result != 0x93f5f5799a932462 {
+		t.Errorf("Expected 0x93f5f5799a932462 but got %#x", result)
+	}
+}
+
+func TestRoundFuncWithinRadix(t *testing.T) {
+	for _, roundFunc := range roundFuncsToTest {
+		t.Run(fmt.Sprintf("%T", roundFunc), func(t *testing.T) {
+			for _, radix := range []uint64{1, 2, 3, 7, 1 << 32} {
+				for value := range uint64(1000) {
+					if result := roundFunc.Round(splitmix64(value), value, radix); result >= radix {
+						t.Fatalf("Round returned %d for radix %d", result, radix)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoundFuncChangesPermutation(t *testing.T) {
+	defaultNet, err := NewNetwork(1000, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sipNet, err := NewNetwork(1000, 1, 8, WithRoundFunc(SipHash24{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nilNet, err := NewNetwork(1000, 1, 8, WithRoundFunc(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	differences := 0
+	for index, value := range defaultNet.All() {
+		sipValue, err := sipNet.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sipValue != value {
+			differences++
+		}
+
+		nilValue, err := nilNet.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nilValue != value {
+			t.Fatalf("A nil round func should fall back to SplitMix64, %d mapped to %d instead of %d", index, nilValue, value)
+		}
+	}
+
+	if differences < 900 {
+		t.Errorf("Expected a different permutation with SipHash24, only %d of 1001 values differ", differences)
+	}
+}
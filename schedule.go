@@ -0,0 +1,50 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKeySchedule is returned when WithEpochKeySchedule is given a value that isn't one of the schedules below
+var ErrUnknownKeySchedule = errors.New("feistel: unknown epoch key schedule")
+
+// EpochKeySchedule selects how the round keys of an epoch (or a tweak) are derived from the seeds
+type EpochKeySchedule uint8
+
+const (
+	// XorEpochKeys xors the same epoch hash into every round key. It's the default and the cheapest but every
+	// round key of one epoch differs from the same round key of another epoch by the same constant.
+	XorEpochKeys EpochKeySchedule = iota
+	// IndependentEpochKeys hashes the epoch hash together with each seed, so the round keys of different epochs
+	// have no structural relationship. It adds one hash per round to every epoch except the first one.
+	IndependentEpochKeys
+)
+
+// String returns the name of the schedule
+func (s EpochKeySchedule) String() string {
+	switch s {
+	case XorEpochKeys:
+		return "XorEpochKeys"
+	case IndependentEpochKeys:
+		return "IndependentEpochKeys"
+	default:
+		return fmt.Sprintf("EpochKeySchedule(%d)", uint8(s))
+	}
+}
+
+// WithEpochKeySchedule is an option that selects how round keys are derived for each epoch, the first epoch
+// (and Map without a tweak) always uses the seeds as they are so it's the same for every schedule
+func WithEpochKeySchedule(schedule EpochKeySchedule) Option {
+	return func(m *Network) {
+		m.keySchedule = schedule
+	}
+}
+
+// roundKey returns the key for round once keyOffset, the epoch hash mixed with the tweak hash, is applied
+func (n *Network) roundKey(round int, keyOffset uint64) uint64 {
+	if n.keySchedule == IndependentEpochKeys && keyOffset != 0 {
+		return splitmix64(n.seeds[round] ^ keyOffset)
+	}
+
+	return n.seeds[round] ^ keyOffset
+}
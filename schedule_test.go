@@ -0,0 +1,154 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var keySchedulesToTest = []EpochKeySchedule{XorEpochKeys, IndependentEpochKeys}
+
+// checkZScores combines independent z scores into a chi squared statistic so many lags can be checked at once
+// without the false failures you'd get from checking each of them against 3σ
+func checkZScores(t *testing.T, zScores []float64, threshold float64) {
+	t.Helper()
+
+	chi2 := 0.0
+	for _, z := range zScores {
+		chi2 += z * z
+	}
+
+	p := distuv.ChiSquared{K: float64(len(zScores))}.Survival(chi2)
+
+	if threshold > p {
+		t.Errorf("Correlations are too large, p is %f, z scores: %v", p, zScores)
+	}
+}
+
+func TestEpochCorrelationManyLags(t *testing.T) {
+	epochs := uint64(4000)
+	maxLag := 32
+
+	for _, schedule := range keySchedulesToTest {
+		for _, maxValue := range []uint64{13, 101, 1000} {
+			t.Run(fmt.Sprintf("schedule %v, maxValue %d", schedule, maxValue), func(t *testing.T) {
+				net, err := NewNetwork(maxValue, 42, 8, WithEpochs(), WithEpochKeySchedule(schedule))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, position := range []uint64{0, maxValue / 2, maxValue} {
+					// The value at the same position in each epoch
+					values := make([]float64, epochs)
+					for epoch := range epochs {
+						mapped, err := net.MapEpoch(epoch, position)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						values[epoch] = float64(mapped)
+					}
+
+					zScores := make([]float64, maxLag)
+					for lag := 1; lag <= maxLag; lag++ {
+						_, zScores[lag-1] = correlationZScore(t, values[:len(values)-lag], values[lag:], 0)
+					}
+
+					checkZScores(t, zScores, 0.001)
+				}
+			})
+		}
+	}
+}
+
+func TestNonAdjacentEpochCorrelation(t *testing.T) {
+	starts := uint64(30)
+
+	for _, schedule := range keySchedulesToTest {
+		for _, maxValue := range []uint64{101, 1000} {
+			t.Run(fmt.Sprintf("schedule %v, maxValue %d", schedule, maxValue), func(t *testing.T) {
+				net, err := NewNetwork(maxValue, 42, 8, WithEpochKeySchedule(schedule))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				permutation := func(epoch uint64) []float64 {
+					values := make([]float64, maxValue+1)
+					view := net.Epoch(epoch)
+
+					for index := range maxValue + 1 {
+						mapped, err := view.Map(index)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						values[index] = float64(mapped)
+					}
+
+					return values
+				}
+
+				for _, distance := range []uint64{2, 3, 7, 16, 100, 1000, 1 << 20, 1 << 40} {
+					zScores := make([]float64, starts)
+
+					for i := range starts {
+						epoch := 1 + i*7919
+						// Two independent permutations of the same values should have no correlation at all
+						_, zScores[i] = correlationZScore(t, permutation(epoch), permutation(epoch+distance), 0)
+					}
+
+					checkZScores(t, zScores, 0.001)
+				}
+			})
+		}
+	}
+}
+
+func TestKeyScheduleFirstEpochUnchanged(t *testing.T) {
+	plain, err := NewNetwork(1000, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	independent, err := NewNetwork(1000, 42, 8, WithEpochs(), WithEpochKeySchedule(IndependentEpochKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	differences := 0
+	for index, value := range plain.Range(0, 2001) {
+		mapped, err := independent.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if index <= 1000 && mapped != value {
+			t.Fatalf("First epoch mapped %d to %d instead of %d", index, mapped, value)
+		}
+
+		if mapped != value {
+			differences++
+		}
+
+		inverted, err := independent.InvertMap(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != index {
+			t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+		}
+	}
+
+	if differences < 900 {
+		t.Errorf("Expected later epochs to change, only %d of 1001 values differ", differences)
+	}
+}
+
+func TestUnknownKeySchedule(t *testing.T) {
+	if _, err := NewNetwork(1000, 42, 8, WithEpochKeySchedule(IndependentEpochKeys+1)); !errors.Is(err, ErrUnknownKeySchedule) {
+		t.Errorf("Expected ErrUnknownKeySchedule, got %v", err)
+	}
+}
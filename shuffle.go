@@ -0,0 +1,83 @@
+package feistel
+
+import "errors"
+
+// MaxShuffleSize is the largest domain WithExactShuffle can be used for, the tables take 8 bytes per value
+const MaxShuffleSize = 1 << 16
+
+// shuffleCacheSize is how many tables a network keeps, enough to alternate between a few epochs or tweaks
+// and for WithEpochBoundarySpacing to read the previous epoch while mapping the current one
+const shuffleCacheSize = 4
+
+// ErrShuffleTooLarge is returned when WithExactShuffle is used for a domain larger than MaxShuffleSize
+var ErrShuffleTooLarge = errors.New("feistel: domain is too large for an exact shuffle")
+
+// WithExactShuffle is an option that replaces the Feistel rounds with a Fisher-Yates shuffle of the whole domain.
+// A Feistel network can only reach a small and uneven part of the n! permutations of a domain, which shows for
+// things like shuffling a deck of cards. The shuffle draws every swap from the round function, so each permutation
+// is equally likely as far as the seed (or the key with NewKeyedNetwork) allows.
+// A table is built the first time an epoch or tweak is used and the last 4 are kept, so it costs O(n) time on
+// the first call for each of them, then O(1) per call. It can only be used up to MaxShuffleSize values.
+func WithExactShuffle() Option {
+	return func(m *Network) {
+		m.exactShuffle = true
+	}
+}
+
+// shuffleTable is the shuffle of the domain for one key offset and its inverse
+type shuffleTable struct {
+	keyOffset uint64
+	forward   []uint32
+	inverse   []uint32
+}
+
+// shuffled maps index through the table for keyOffset, building it and replacing the oldest table if it isn't cached
+func (n *Network) shuffled(index, keyOffset uint64, invert bool) uint64 {
+	table := n.cachedShuffle(keyOffset)
+	if table == nil {
+		table = n.buildShuffle(keyOffset)
+		n.shuffleTables[n.shuffleNext.Add(1)%shuffleCacheSize].Store(table)
+	}
+
+	if invert {
+		return uint64(table.inverse[index])
+	}
+
+	return uint64(table.forward[index])
+}
+
+func (n *Network) cachedShuffle(keyOffset uint64) *shuffleTable {
+	for i := range n.shuffleTables {
+		if table := n.shuffleTables[i].Load(); table != nil && table.keyOffset == keyOffset {
+			return table
+		}
+	}
+
+	return nil
+}
+
+func (n *Network) buildShuffle(keyOffset uint64) *shuffleTable {
+	domainSize := n.maxValue + 1
+	table := &shuffleTable{
+		keyOffset: keyOffset,
+		forward:   make([]uint32, domainSize),
+		inverse:   make([]uint32, domainSize),
+	}
+
+	for i := range table.forward {
+		table.forward[i] = uint32(i)
+	}
+
+	// Every swap is keyed with the first round key and hashes its position, so j is uniform in [0, i]
+	key := n.roundKey(0, keyOffset)
+	for i := domainSize - 1; i > 0; i-- {
+		j := n.roundFunc.Round(key, i, i+1)
+		table.forward[i], table.forward[j] = table.forward[j], table.forward[i]
+	}
+
+	for i, value := range table.forward {
+		table.inverse[value] = uint32(i)
+	}
+
+	return table
+}
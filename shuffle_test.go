@@ -0,0 +1,200 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExactShuffleIsPermutation(t *testing.T) {
+	for _, maxValue := range []uint64{1, 12, 51, 1000, MaxShuffleSize - 1} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8, WithExactShuffle(), WithEpochs())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, epoch := range []uint64{0, 3} {
+				seen := make(map[uint64]struct{}, maxValue+1)
+				epochStart := epoch * (maxValue + 1)
+
+				for index := epochStart; index <= epochStart+maxValue; index++ {
+					mapped, err := net.Map(index)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if _, ok := seen[mapped]; ok || mapped < epochStart || mapped > epochStart+maxValue {
+						t.Fatalf("Mapped %d to %d which was either seen before or outside of epoch %d", index, mapped, epoch)
+					}
+					seen[mapped] = struct{}{}
+
+					inverted, err := net.InvertMap(mapped)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if inverted != index {
+						t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestExactShuffleEpochsAndTweaks(t *testing.T) {
+	net, err := NewNetwork(51, 42, 8, WithExactShuffle(), WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := make([]uint64, 52)
+	second := make([]uint64, 52)
+	tweaked := make([]uint64, 52)
+
+	// Alternate between the epochs and the tweak so every call uses a different table
+	for i := range uint64(52) {
+		first[i], _ = net.Map(i)
+		second[i], _ = net.Map(52 + i)
+		second[i] -= 52
+		tweaked[i], _ = net.MapWithTweak(i, []byte("tenant"))
+	}
+
+	for i := range uint64(52) {
+		if mapped, _ := net.Map(i); mapped != first[i] {
+			t.Fatalf("Expected %d to map to %d again, got %d", i, first[i], mapped)
+		}
+	}
+
+	if fmt.Sprint(first) == fmt.Sprint(second) || fmt.Sprint(first) == fmt.Sprint(tweaked) {
+		t.Errorf("Expected the epochs and the tweak to produce different shuffles")
+	}
+}
+
+func TestExactShuffleCachesTables(t *testing.T) {
+	calls := 0
+	net, err := NewNetwork(MaxShuffleSize-1, 42, 8, WithExactShuffle(), WithEpochs(), WithRoundFunc(countingRoundFunc{&calls}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each table takes one round call per value except the first one to build
+	for i := range uint64(2000) {
+		if _, err := net.Map(i % 2 * MaxShuffleSize); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 2*(MaxShuffleSize-1) {
+		t.Errorf("Expected alternating epochs to build 2 tables with %d round calls, got %d", 2*(MaxShuffleSize-1), calls)
+	}
+}
+
+func TestExactShuffleWithBoundarySpacing(t *testing.T) {
+	const domainSize, spacing, epochs = 100, 10, 20
+
+	calls := 0
+	net, err := NewNetwork(domainSize-1, 42, 8, WithExactShuffle(), WithEpochs(), WithEpochBoundarySpacing(spacing), WithRoundFunc(countingRoundFunc{&calls}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make([]uint64, domainSize*epochs)
+	if err := net.MapRange(values, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the previous epoch for the swaps mustn't push the current table out, so every table is built once
+	if calls != epochs*(domainSize-1) {
+		t.Errorf("Expected %d tables to take %d round calls, got %d", epochs, epochs*(domainSize-1), calls)
+	}
+
+	for epoch := range uint64(epochs) {
+		start := epoch * domainSize
+		seen := make(map[uint64]struct{}, domainSize)
+
+		for i, value := range values[start : start+domainSize] {
+			if _, ok := seen[value]; ok || value < start || value >= start+domainSize {
+				t.Fatalf("%d isn't a new value of epoch %d", value, epoch)
+			}
+			seen[value] = struct{}{}
+
+			if inverted, err := net.InvertMap(value); err != nil || inverted != start+uint64(i) {
+				t.Fatalf("Mapped %d to %d and inversion produced %d, %v", start+uint64(i), value, inverted, err)
+			}
+		}
+
+		if epoch == 0 {
+			continue
+		}
+
+		tail := make(map[uint64]struct{}, spacing)
+		for _, value := range values[start-spacing : start] {
+			tail[value%domainSize] = struct{}{}
+		}
+
+		for _, value := range values[start : start+spacing] {
+			if _, ok := tail[value%domainSize]; ok {
+				t.Fatalf("%d appears within %d positions on both sides of the start of epoch %d", value%domainSize, spacing, epoch)
+			}
+		}
+	}
+}
+
+func TestExactShuffleIsUniform(t *testing.T) {
+	// Every one of the 4! orders of a 4 value domain should be about as likely across seeds
+	counts := make(map[[4]uint64]uint64)
+	for seed := range uint64(24 * 400) {
+		net, err := NewNetwork(3, seed, 1, WithExactShuffle())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var order [4]uint64
+		for i := range order {
+			order[i], _ = net.Map(uint64(i))
+		}
+		counts[order]++
+	}
+
+	if len(counts) != 24 {
+		t.Fatalf("Expected all 24 permutations, got %d", len(counts))
+	}
+
+	values := make([]uint64, 0, len(counts))
+	for _, count := range counts {
+		values = append(values, count)
+	}
+	chiSquaredTest(t, values, 0.01)
+}
+
+func TestExactShuffleKeyed(t *testing.T) {
+	net, err := NewKeyedNetwork(9, testKey128, 4, WithExactShuffle())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[uint64]struct{})
+	for index := range uint64(10) {
+		mapped, err := net.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[mapped] = struct{}{}
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("Expected 10 distinct values, got %d", len(seen))
+	}
+}
+
+func TestExactShuffleTooLarge(t *testing.T) {
+	if _, err := NewNetwork(MaxShuffleSize, 42, 8, WithExactShuffle()); !errors.Is(err, ErrShuffleTooLarge) {
+		t.Errorf("Expected ErrShuffleTooLarge, got %v", err)
+	}
+
+	if _, err := NewNetwork(^uint64(0), 42, 8, WithExactShuffle()); !errors.Is(err, ErrShuffleTooLarge) {
+		t.Errorf("Expected ErrShuffleTooLarge, got %v", err)
+	}
+}
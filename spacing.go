@@ -0,0 +1,154 @@
+package feistel
+
+import "errors"
+
+// swapCacheSize is how many swap tables a network keeps, enough to alternate between a few epochs or tweaks
+const swapCacheSize = 4
+
+// ErrBoundarySpacingTooLarge is returned when the epoch boundary spacing is more than a third of the domain size
+var ErrBoundarySpacingTooLarge = errors.New("feistel: epoch boundary spacing cannot be greater than a third of the domain size")
+
+// WithEpochBoundarySpacing is an option that guarantees no value emitted in the last k positions of an epoch
+// is emitted again in the first k positions of the next epoch, so something like a load balancer walking the
+// epochs never hands out the same value twice within k calls. Each epoch is still a full permutation and Map
+// and InvertMap stay bijective, the first epoch is never changed.
+// It works by swapping the values at the start of an epoch that collide with the end of the previous epoch
+// with values from the positions right after them. The swaps of an epoch are worked out the first time one of its
+// first 2k positions is mapped, which costs roughly 3k extra runs of the network, and the last 4 epochs or tweaks
+// are kept so alternating between a few of them doesn't work them out again. k can be at most a third of
+// max value + 1 and 0 disables it.
+func WithEpochBoundarySpacing(k uint64) Option {
+	return func(m *Network) {
+		m.boundarySpacing = k
+	}
+}
+
+func maxBoundarySpacing(maxValue uint64) uint64 {
+	if maxValue == ^uint64(0) {
+		return maxValue / 3
+	}
+
+	return (maxValue + 1) / 3
+}
+
+// spacedPermute is permute for epochs affected by WithEpochBoundarySpacing. Only the first 2k positions
+// of the epoch can be swapped and the last k positions are never touched (since k is at most a third of the domain)
+// so the tail of the previous epoch can be read straight from its unmodified permutation.
+func (n *Network) spacedPermute(epoch, keyOffset, tweakHash, index uint64, invert bool, cache *roundCache) (uint64, error) {
+	k := n.boundarySpacing
+
+	if invert {
+		position, err := n.cycleWalk(index, keyOffset, true, cache)
+		if err != nil || position >= 2*k {
+			return position, err
+		}
+
+		return n.boundarySwap(epoch, keyOffset, tweakHash, position)
+	}
+
+	if index >= 2*k {
+		return n.cycleWalk(index, keyOffset, false, cache)
+	}
+
+	position, err := n.boundarySwap(epoch, keyOffset, tweakHash, index)
+	if err != nil {
+		return 0, err
+	}
+
+	return n.cycleWalk(position, keyOffset, false, nil)
+}
+
+// swapTable holds the swapped positions at the start of one epoch for one tweak, each position maps to its partner
+type swapTable struct {
+	epoch     uint64
+	tweakHash uint64
+	swaps     map[uint64]uint64
+}
+
+// boundarySwap returns the position that position is swapped with at the start of epoch, or position itself.
+// The swaps are worked out once per epoch and tweak, building them replaces the oldest table if they aren't cached.
+func (n *Network) boundarySwap(epoch, keyOffset, tweakHash, position uint64) (uint64, error) {
+	table := n.cachedSwaps(epoch, tweakHash)
+	if table == nil {
+		var err error
+		table, err = n.buildSwaps(epoch, keyOffset, tweakHash)
+		if err != nil {
+			return 0, err
+		}
+		n.swapTables[n.swapNext.Add(1)%swapCacheSize].Store(table)
+	}
+
+	if partner, ok := table.swaps[position]; ok {
+		return partner, nil
+	}
+
+	return position, nil
+}
+
+func (n *Network) cachedSwaps(epoch, tweakHash uint64) *swapTable {
+	for i := range n.swapTables {
+		if table := n.swapTables[i].Load(); table != nil && table.epoch == epoch && table.tweakHash == tweakHash {
+			return table
+		}
+	}
+
+	return nil
+}
+
+// buildSwaps pairs each of the first k positions holding a value from the tail of the previous epoch, in order,
+// with the next position from k onwards that holds a value outside of that tail.
+// The walk limit is for the index being mapped, so these extra runs of the network walk without it.
+func (n *Network) buildSwaps(epoch, keyOffset, tweakHash uint64) (*swapTable, error) {
+	k := n.boundarySpacing
+	previousOffset := n.epochHash(epoch-1) ^ tweakHash
+
+	walker := n
+	if n.unbounded != nil {
+		walker = n.unbounded
+	}
+
+	tail := make(map[uint64]struct{}, k)
+	for i := range k {
+		value, err := walker.cycleWalk(n.maxValue-i, previousOffset, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		tail[value] = struct{}{}
+	}
+
+	table := &swapTable{
+		epoch:     epoch,
+		tweakHash: tweakHash,
+		swaps:     make(map[uint64]uint64),
+	}
+
+	partner := k
+	for conflict := range k {
+		value, err := walker.cycleWalk(conflict, keyOffset, false, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := tail[value]; !ok {
+			continue
+		}
+
+		for {
+			value, err := walker.cycleWalk(partner, keyOffset, false, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := tail[value]; !ok {
+				break
+			}
+			partner++
+		}
+
+		table.swaps[conflict] = partner
+		table.swaps[partner] = conflict
+		partner++
+	}
+
+	return table, nil
+}
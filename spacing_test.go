@@ -0,0 +1,207 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEpochBoundarySpacing(t *testing.T) {
+	tests := []struct {
+		maxValue uint64
+		spacing  uint64
+	}{
+		{2, 1},
+		{9, 1},
+		{9, 3},
+		{13, 4},
+		{100, 1},
+		{100, 10},
+		{100, 33},
+		{1000, 100},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("maxValue %d, spacing %d", test.maxValue, test.spacing), func(t *testing.T) {
+			net, err := NewNetwork(test.maxValue, 42, 8, WithEpochs(), WithEpochBoundarySpacing(test.spacing))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			domainSize := test.maxValue + 1
+			epochs := uint64(50)
+			values := make([]uint64, domainSize*epochs)
+
+			if err := net.MapRange(values, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			for epoch := range epochs {
+				start := epoch * domainSize
+				seen := make(map[uint64]struct{}, domainSize)
+
+				for i, value := range values[start : start+domainSize] {
+					index := start + uint64(i)
+					if value < start || value >= start+domainSize {
+						t.Fatalf("Mapped %d to %d which is outside of epoch %d", index, value, epoch)
+					}
+
+					if _, ok := seen[value]; ok {
+						t.Fatalf("%d was mapped twice in epoch %d", value, epoch)
+					}
+					seen[value] = struct{}{}
+
+					inverted, err := net.InvertMap(value)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if inverted != index {
+						t.Fatalf("Mapped %d to %d and inversion produced %d", index, value, inverted)
+					}
+
+					single, err := net.Map(index)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if single != value {
+						t.Fatalf("MapRange mapped %d to %d but Map returned %d", index, value, single)
+					}
+				}
+
+				if epoch == 0 {
+					continue
+				}
+
+				// The elements handed out at the end of the previous epoch can't be at the start of this one
+				tail := make(map[uint64]struct{}, test.spacing)
+				for _, value := range values[start-test.spacing : start] {
+					tail[value%domainSize] = struct{}{}
+				}
+
+				for _, value := range values[start : start+test.spacing] {
+					if _, ok := tail[value%domainSize]; ok {
+						t.Fatalf("%d appears within %d positions on both sides of the start of epoch %d", value%domainSize, test.spacing, epoch)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEpochBoundarySpacingMapEpoch(t *testing.T) {
+	net, err := NewNetwork(99, 42, 8, WithEpochs(), WithEpochBoundarySpacing(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for value := uint64(0); value < 1000; value++ {
+		expected, err := net.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		epoch, offset := net.EpochOf(value)
+		mapped, err := net.MapEpoch(epoch, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped+epoch*100 != expected {
+			t.Fatalf("MapEpoch(%d, %d) returned %d but Map(%d) returned %d", epoch, offset, mapped, value, expected)
+		}
+	}
+}
+
+func TestEpochBoundarySpacingDisabled(t *testing.T) {
+	plain, err := NewNetwork(100, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spaced, err := NewNetwork(100, 42, 8, WithEpochs(), WithEpochBoundarySpacing(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, value := range plain.Range(0, 1000) {
+		mapped, err := spaced.Map(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != value {
+			t.Fatalf("Spacing of 0 mapped %d to %d instead of %d", index, mapped, value)
+		}
+	}
+}
+
+func TestEpochBoundarySpacingCachesTables(t *testing.T) {
+	const spacing = 30
+
+	calls := 0
+	net, err := NewNetwork(100, 42, 8, WithEpochs(), WithEpochBoundarySpacing(spacing), WithRoundFunc(countingRoundFunc{&calls}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tweaks := [][]byte{[]byte("a"), []byte("b")}
+
+	// Build the swaps for both tweaks in both epochs once, which fills the cache
+	for _, tweak := range tweaks {
+		for _, index := range []uint64{101, 202} {
+			if _, err := net.MapWithTweak(index, tweak); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	calls = 0
+	for i := range uint64(300) {
+		if _, err := net.MapWithTweak(101+i%2*101+i%spacing, tweaks[i/2%2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Building the swaps takes roughly 3 * spacing runs of the network, mapping a position takes a couple of runs
+	if calls >= 300*8*spacing/2 {
+		t.Errorf("Expected alternating tweaks and epochs to reuse the swaps, got %d round calls for 300 maps", calls)
+	}
+}
+
+func TestEpochBoundarySpacingTooLarge(t *testing.T) {
+	if _, err := NewNetwork(8, 42, 8, WithEpochBoundarySpacing(3)); err != nil {
+		t.Errorf("Expected spacing of a third of the domain to be allowed, got %v", err)
+	}
+
+	if _, err := NewNetwork(8, 42, 8, WithEpochBoundarySpacing(4)); !errors.Is(err, ErrBoundarySpacingTooLarge) {
+		t.Errorf("Expected ErrBoundarySpacingTooLarge, got %v", err)
+	}
+
+	if _, err := NewNetwork(^uint64(0), 42, 8, WithEpochBoundarySpacing(1)); err != nil {
+		t.Errorf("Expected spacing to be allowed on the full range, got %v", err)
+	}
+}
+
+func BenchmarkEpochBoundarySpacing(b *testing.B) {
+	const maxValue = 99_999
+
+	for _, spacing := range []uint64{0, 1000, 30_000} {
+		b.Run(fmt.Sprintf("spacing %d", spacing), func(b *testing.B) {
+			net, err := NewNetwork(maxValue, 42, 8, WithEpochs(), WithEpochBoundarySpacing(spacing))
+			if err != nil {
+				b.Fatalf("Unable to create network with error: %v", err)
+			}
+
+			dst := make([]uint64, maxValue+1)
+
+			// Every iteration maps the whole of a new epoch so its swaps have to be worked out again
+			for i := range b.N {
+				if err := net.MapRange(dst, uint64(i+1)*(maxValue+1)); err != nil {
+					b.Fatalf("Failed mapping with error %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"unicode/utf8"
+)
+
+// ErrInvalidAlphabet is returned when an alphabet has fewer than 2 characters or repeats a character
+var ErrInvalidAlphabet = errors.New("feistel: alphabet must have at least 2 unique characters")
+
+// ErrCharacterNotInAlphabet is returned when a string has a character that isn't in the alphabet
+var ErrCharacterNotInAlphabet = errors.New("feistel: character is not in the alphabet")
+
+// ErrInvalidLength is returned when a string length is outside of the lengths a StringNetwork was created for,
+// or when that many characters can't be represented in a uint64
+var ErrInvalidLength = errors.New("feistel: invalid string length")
+
+// StringNetwork permutes strings of characters from an alphabet to other strings of the same length from the same alphabet.
+// Each string is read as a number in a radix of the alphabet size (the first character of the alphabet is 0) and mapped by
+// a Network over all the strings of its length, so the seed and options behave the same way they do for Network.
+type StringNetwork struct {
+	alphabet  []rune
+	lookup    map[rune]uint64
+	radix     uint64
+	minLength int
+	networks  []*Network
+}
+
+// NewStringNetwork creates a StringNetwork for strings from minLength to maxLength characters long,
+// alphabet is the list of characters that can be used. radix^maxLength can't be larger than 2^64.
+// seed, rounds and opts are passed to NewNetwork for each length.
+func NewStringNetwork(alphabet string, minLength, maxLength int, seed uint64, rounds uint8, opts ...Option) (*StringNetwork, error) {
+	runes := []rune(alphabet)
+	lookup := make(map[rune]uint64, len(runes))
+
+	for i, r := range runes {
+		if _, ok := lookup[r]; ok {
+			return nil, fmt.Errorf("%w, %q is repeated", ErrInvalidAlphabet, r)
+		}
+		lookup[r] = uint64(i)
+	}
+
+	if len(runes) < 2 {
+		return nil, fmt.Errorf("%w, alphabet: %q", ErrInvalidAlphabet, alphabet)
+	}
+
+	if minLength < 1 || minLength > maxLength {
+		return nil, fmt.Errorf("%w, minLength: %d, maxLength: %d", ErrInvalidLength, minLength, maxLength)
+	}
+
+	network := &StringNetwork{
+		alphabet:  runes,
+		lookup:    lookup,
+		radix:     uint64(len(runes)),
+		minLength: minLength,
+		networks:  make([]*Network, 0, maxLength-minLength+1),
+	}
+
+	for length := minLength; length <= maxLength; length++ {
+		maxValue, ok := maxNumeral(network.radix, length)
+		if !ok {
+			return nil, fmt.Errorf("%w, %d characters from an alphabet of %d don't fit in a uint64", ErrInvalidLength, length, len(runes))
+		}
+
+		lengthNetwork, err := NewNetwork(maxValue, seed, rounds, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		network.networks = append(network.networks, lengthNetwork)
+	}
+
+	return network, nil
+}
+
+// Map takes a string and maps it to another string of the same length
+func (n *StringNetwork) Map(s string) (string, error) {
+	return n.encode(s, func(network *Network, value uint64) (uint64, error) {
+		return network.Map(value)
+	})
+}
+
+// InvertMap performs an inversion of Map
+func (n *StringNetwork) InvertMap(s string) (string, error) {
+	return n.encode(s, func(network *Network, value uint64) (uint64, error) {
+		return network.InvertMap(value)
+	})
+}
+
+// MapEpoch maps a string within the given epoch, see Network.MapEpoch
+func (n *StringNetwork) MapEpoch(epoch uint64, s string) (string, error) {
+	return n.encode(s, func(network *Network, value uint64) (uint64, error) {
+		return network.MapEpoch(epoch, value)
+	})
+}
+
+// InvertMapEpoch performs an inversion of MapEpoch
+func (n *StringNetwork) InvertMapEpoch(epoch uint64, s string) (string, error) {
+	return n.encode(s, func(network *Network, value uint64) (uint64, error) {
+		return network.InvertMapEpoch(epoch, value)
+	})
+}
+
+// Network returns the Network used for strings of the given length, or nil if the length isn't supported
+func (n *StringNetwork) Network(length int) *Network {
+	if length < n.minLength || length >= n.minLength+len(n.networks) {
+		return nil
+	}
+
+	return n.networks[length-n.minLength]
+}
+
+// Parse converts a string into the number it represents in the alphabet's radix
+func (n *StringNetwork) Parse(s string) (uint64, error) {
+	length := utf8.RuneCountInString(s)
+	if n.Network(length) == nil {
+		return 0, fmt.Errorf("%w, length: %d, minLength: %d, maxLength: %d", ErrInvalidLength, length, n.minLength, n.minLength+len(n.networks)-1)
+	}
+
+	var value uint64
+	for _, r := range s {
+		digit, ok := n.lookup[r]
+		if !ok {
+			return 0, fmt.Errorf("%w, character: %q", ErrCharacterNotInAlphabet, r)
+		}
+
+		value = value*n.radix + digit
+	}
+
+	return value, nil
+}
+
+// Format performs an inversion of Parse, writing value with exactly length characters
+func (n *StringNetwork) Format(value uint64, length int) (string, error) {
+	network := n.Network(length)
+	if network == nil {
+		return "", fmt.Errorf("%w, length: %d, minLength: %d, maxLength: %d", ErrInvalidLength, length, n.minLength, n.minLength+len(n.networks)-1)
+	}
+
+	if value > network.maxValue {
+		return "", fmt.Errorf("%w, index: %d, maxSize: %d", ErrIndexGreatThanMaxValue, value, network.maxValue)
+	}
+
+	result := make([]rune, length)
+	for i := length - 1; i >= 0; i-- {
+		result[i] = n.alphabet[value%n.radix]
+		value /= n.radix
+	}
+
+	return string(result), nil
+}
+
+func (n *StringNetwork) encode(s string, fn func(*Network, uint64) (uint64, error)) (string, error) {
+	value, err := n.Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	length := utf8.RuneCountInString(s)
+
+	mapped, err := fn(n.Network(length), value)
+	if err != nil {
+		return "", err
+	}
+
+	return n.Format(mapped, length)
+}
+
+// maxNumeral returns radix^length - 1 and false if radix^length is larger than 2^64
+func maxNumeral(radix uint64, length int) (uint64, bool) {
+	size := uint64(1)
+
+	for i := range length {
+		hi, lo := bits.Mul64(size, radix)
+
+		// Exactly 2^64 is still fine, the max value is then the largest uint64
+		if hi == 1 && lo == 0 && i == length-1 {
+			return ^uint64(0), true
+		}
+
+		if hi != 0 {
+			return 0, false
+		}
+
+		size = lo
+	}
+
+	return size - 1, true
+}
@@ -0,0 +1,183 @@
+package feistel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStringNetworkPermutation(t *testing.T) {
+	alphabet := "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	net, err := NewStringNetwork(alphabet, 1, 3, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for length := 1; length <= 3; length++ {
+		seen := make(map[string]struct{})
+		total := uint64(1)
+		for range length {
+			total *= uint64(len(alphabet))
+		}
+
+		for value := range total {
+			s, err := net.Format(value, length)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mapped, err := net.Map(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if utf8.RuneCountInString(mapped) != length {
+				t.Fatalf("Mapped %q to %q which has a different length", s, mapped)
+			}
+
+			for _, r := range mapped {
+				if !strings.ContainsRune(alphabet, r) {
+					t.Fatalf("Mapped %q to %q which isn't in the alphabet", s, mapped)
+				}
+			}
+
+			if _, ok := seen[mapped]; ok {
+				t.Fatalf("%q was mapped twice", mapped)
+			}
+			seen[mapped] = struct{}{}
+
+			inverted, err := net.InvertMap(mapped)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if inverted != s {
+				t.Fatalf("Mapped %q to %q and inversion produced %q", s, mapped, inverted)
+			}
+		}
+	}
+}
+
+func TestStringNetworkMatchesNetwork(t *testing.T) {
+	net, err := NewStringNetwork("0123456789", 4, 4, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	numbers, err := NewNetwork(9999, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []uint64{0, 7, 1234, 9999} {
+		s, err := net.Format(value, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := numbers.Map(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mapped, err := net.Map(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed, err := net.Parse(mapped); err != nil || parsed != expected {
+			t.Errorf("Expected %q to map to %d but got %q", s, expected, mapped)
+		}
+
+		expectedEpoch, err := numbers.MapEpoch(3, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mappedEpoch, err := net.MapEpoch(3, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed, err := net.Parse(mappedEpoch); err != nil || parsed != expectedEpoch {
+			t.Errorf("Expected %q to map to %d in epoch 3 but got %q", s, expectedEpoch, mappedEpoch)
+		}
+
+		if inverted, err := net.InvertMapEpoch(3, mappedEpoch); err != nil || inverted != s {
+			t.Errorf("Mapped %q to %q in epoch 3 and inversion produced %q, %v", s, mappedEpoch, inverted, err)
+		}
+	}
+}
+
+func TestStringNetworkUnicode(t *testing.T) {
+	net, err := NewStringNetwork("αβγδ", 6, 6, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapped, err := net.Map("αααβββ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if utf8.RuneCountInString(mapped) != 6 {
+		t.Errorf("Expected 6 characters, got %q", mapped)
+	}
+
+	if inverted, err := net.InvertMap(mapped); err != nil || inverted != "αααβββ" {
+		t.Errorf("Mapped αααβββ to %q and inversion produced %q, %v", mapped, inverted, err)
+	}
+}
+
+func TestStringNetworkFullUint64(t *testing.T) {
+	// 16^16 is exactly 2^64
+	net, err := NewStringNetwork("0123456789abcdef", 16, 16, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapped, err := net.Map("ffffffffffffffff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inverted, err := net.InvertMap(mapped); err != nil || inverted != "ffffffffffffffff" {
+		t.Errorf("Mapped ffffffffffffffff to %q and inversion produced %q, %v", mapped, inverted, err)
+	}
+
+	if _, err := NewStringNetwork("0123456789abcdef", 16, 17, 1, 8); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength for 17 hex characters, got %v", err)
+	}
+}
+
+func TestStringNetworkErrors(t *testing.T) {
+	for _, alphabet := range []string{"", "a", "abca"} {
+		if _, err := NewStringNetwork(alphabet, 1, 2, 1, 8); !errors.Is(err, ErrInvalidAlphabet) {
+			t.Errorf("Expected ErrInvalidAlphabet for %q, got %v", alphabet, err)
+		}
+	}
+
+	if _, err := NewStringNetwork("abc", 3, 2, 1, 8); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+
+	net, err := NewStringNetwork("abc", 2, 4, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"a", "abcab"} {
+		if _, err := net.Map(s); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("Expected ErrInvalidLength for %q, got %v", s, err)
+		}
+	}
+
+	if _, err := net.Map("abd"); !errors.Is(err, ErrCharacterNotInAlphabet) {
+		t.Errorf("Expected ErrCharacterNotInAlphabet, got %v", err)
+	}
+
+	if _, err := net.Format(9, 2); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+}
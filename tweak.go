@@ -0,0 +1,41 @@
+package feistel
+
+import "encoding/binary"
+
+// MapWithTweak maps index with a permutation selected by tweak, so a single Network can serve a distinct
+// permutation per tenant, user or anything else you can write as bytes without storing a Network for each.
+// The tweak is hashed to 64 bits and xored into every round key the same way the epoch is with WithEpochs,
+// which means different tweaks are as independent from each other as different seeds or epochs are.
+// Like the rest of the package that isn't a cryptographic guarantee unless the network came from NewKeyedNetwork.
+// A nil or empty tweak gives the same result as Map.
+func (n *Network) MapWithTweak(index uint64, tweak []byte) (uint64, error) {
+	return n.encode(index, hashTweak(tweak), false)
+}
+
+// InvertMapWithTweak performs an inversion of MapWithTweak, tweak has to be the same one used to map the value
+func (n *Network) InvertMapWithTweak(index uint64, tweak []byte) (uint64, error) {
+	return n.encode(index, hashTweak(tweak), true)
+}
+
+// hashTweak reduces tweak to 64 bits, the length is hashed first so tweaks that only differ by trailing zeros
+// don't collide
+func hashTweak(tweak []byte) uint64 {
+	if len(tweak) == 0 {
+		return 0
+	}
+
+	hash := splitmix64(uint64(len(tweak)))
+
+	for len(tweak) >= 8 {
+		hash = splitmix64(hash ^ binary.LittleEndian.Uint64(tweak))
+		tweak = tweak[8:]
+	}
+
+	if len(tweak) > 0 {
+		var buf [8]byte
+		copy(buf[:], tweak)
+		hash = splitmix64(hash ^ binary.LittleEndian.Uint64(buf[:]))
+	}
+
+	return hash
+}
@@ -0,0 +1,198 @@
+package feistel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func tenantTweak(tenant uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, tenant)
+}
+
+func TestMapWithTweakInvertible(t *testing.T) {
+	for _, maxValue := range []uint64{0, 13, 1000} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for tenant := range uint64(10) {
+				tweak := tenantTweak(tenant)
+				seen := make(map[uint64]struct{}, maxValue+1)
+
+				for index := range maxValue + 1 {
+					mapped, err := net.MapWithTweak(index, tweak)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if _, ok := seen[mapped]; ok {
+						t.Fatalf("%d was mapped twice for tenant %d", mapped, tenant)
+					}
+					seen[mapped] = struct{}{}
+
+					inverted, err := net.InvertMapWithTweak(mapped, tweak)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if inverted != index {
+						t.Fatalf("Mapped %d to %d and inversion produced %d for tenant %d", index, mapped, inverted, tenant)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMapWithTweakEpochs(t *testing.T) {
+	net, err := NewNetwork(99, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index := uint64(0); index < 1000; index += 7 {
+		mapped, err := net.MapWithTweak(index, []byte("tenant"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped/100 != index/100 {
+			t.Fatalf("Mapped %d to %d which is outside its epoch", index, mapped)
+		}
+
+		inverted, err := net.InvertMapWithTweak(mapped, []byte("tenant"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != index {
+			t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+		}
+	}
+}
+
+func TestEmptyTweakMatchesMap(t *testing.T) {
+	net, err := NewNetwork(1000, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, value := range net.All() {
+		for _, tweak := range [][]byte{nil, {}} {
+			mapped, err := net.MapWithTweak(index, tweak)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mapped != value {
+				t.Fatalf("Empty tweak mapped %d to %d but Map returned %d", index, mapped, value)
+			}
+		}
+	}
+}
+
+func TestHashTweakDistinct(t *testing.T) {
+	tweaks := [][]byte{{0}, {0, 0}, {1}, []byte("tenant-1"), []byte("tenant-10"), []byte("tenant-1\x00")}
+	seen := make(map[uint64]string, len(tweaks))
+
+	for _, tweak := range tweaks {
+		hash := hashTweak(tweak)
+		if previous, ok := seen[hash]; ok {
+			t.Errorf("Tweaks %q and %q have the same hash", previous, tweak)
+		}
+		seen[hash] = string(tweak)
+	}
+}
+
+func TestSerialCorrelationAcrossTweaks(t *testing.T) {
+	for _, maxValue := range []uint64{101, 1000, 50_000} {
+		t.Run(fmt.Sprintf("maxValue %d", maxValue), func(t *testing.T) {
+			net, err := NewNetwork(maxValue, 42, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			previous := make([]float64, maxValue+1)
+			current := make([]float64, maxValue+1)
+
+			for tenant := range uint64(10) {
+				for index := range maxValue + 1 {
+					mapped, err := net.MapWithTweak(index, tenantTweak(tenant))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					current[index] = float64(mapped)
+				}
+
+				// Two independent permutations of the same values should have no correlation at all
+				if tenant > 0 {
+					checkCorrelation(t, previous, current, 0)
+				}
+
+				previous, current = current, previous
+			}
+		})
+	}
+}
+
+func TestSerialCorrelationSameIndexAcrossTweaks(t *testing.T) {
+	net, err := NewNetwork(1000, 42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenants := uint64(5000)
+
+	for _, index := range []uint64{0, 1, 500, 1000} {
+		t.Run(fmt.Sprintf("index %d", index), func(t *testing.T) {
+			values := make([]float64, tenants)
+
+			for tenant := range tenants {
+				mapped, err := net.MapWithTweak(index, tenantTweak(tenant))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				values[tenant] = float64(mapped)
+			}
+
+			checkCorrelation(t, values[:tenants-1], values[1:], 0)
+		})
+	}
+}
+
+// checkCorrelation fails the test if the correlation between current and next is more than 3σ away from expected
+func checkCorrelation(t *testing.T, current, next []float64, expected float64) {
+	t.Helper()
+
+	corr, zScore := correlationZScore(t, current, next, expected)
+
+	if math.Abs(zScore) > 3.0 {
+		t.Errorf("correlation out of band: corr=%.5f, z=%.2f (expected≈%.5f, SE≈%.3f)",
+			corr, zScore, expected, 1.0/math.Sqrt(float64(len(current)-3)))
+	}
+}
+
+// correlationZScore returns the correlation between current and next and how many standard errors it is from expected
+func correlationZScore(t *testing.T, current, next []float64, expected float64) (float64, float64) {
+	t.Helper()
+
+	corr := stat.Correlation(current, next, nil)
+	if math.IsNaN(corr) {
+		t.Fatal("correlation is NaN")
+	}
+
+	// Fisher z transform: z = atanh(r); SE ≈ 1/sqrt(n-3)
+	z := 0.5 * math.Log((1+corr)/(1-corr))
+	zExp := 0.5 * math.Log((1+expected)/(1-expected))
+	se := 1.0 / math.Sqrt(float64(len(current)-3))
+
+	return corr, (z - zExp) / se
+}
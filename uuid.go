@@ -0,0 +1,155 @@
+package feistel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrNotUUIDv4 is returned when a UUID doesn't have the version 4 and RFC 9562 variant bits set
+var ErrNotUUIDv4 = errors.New("feistel: not a version 4 UUID")
+
+const (
+	uuidHalfBits = 61
+	uuidHalfMask = 1<<uuidHalfBits - 1
+	// uuidMaxHi is the largest high word of a 122 bit value
+	uuidMaxHi = 1<<(122-64) - 1
+)
+
+// UUIDNetwork permutes the 122 bit space behind version 4 UUIDs, so sequential counters can be turned into
+// identifiers that look random but can be turned back into the counter.
+// The space splits exactly into two radices of 2^61 so there's never any cycle walking.
+type UUIDNetwork struct {
+	base *Network
+}
+
+// NewUUIDNetwork creates a UUIDNetwork, seed, rounds and opts work the same as in NewNetwork except that there's
+// nothing past the end of a 122 bit space so the options for epochs have no effect.
+// Use WithRoundFunc with an AES round function if the UUIDs shouldn't be predictable.
+func NewUUIDNetwork(seed uint64, rounds uint8, opts ...Option) (*UUIDNetwork, error) {
+	base, err := NewNetwork(^uint64(0), seed, rounds, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UUIDNetwork{base: base}, nil
+}
+
+// Map takes a 122 bit value split into its high 58 bits and low 64 bits and maps it to another 122 bit value
+func (n *UUIDNetwork) Map(hi, lo uint64) (uint64, uint64, error) {
+	return n.encode(hi, lo, false)
+}
+
+// InvertMap performs an inversion of Map
+func (n *UUIDNetwork) InvertMap(hi, lo uint64) (uint64, uint64, error) {
+	return n.encode(hi, lo, true)
+}
+
+// UUIDFromCounter maps counter and writes the result into the random bits of a version 4 UUID
+func (n *UUIDNetwork) UUIDFromCounter(counter uint64) [16]byte {
+	// A counter is always inside the 122 bit space so this can't fail
+	hi, lo, _ := n.encode(0, counter, false)
+	return packUUID(hi, lo)
+}
+
+// CounterFromUUID performs an inversion of UUIDFromCounter, it returns an error if u isn't a version 4 UUID
+// or if it couldn't have been generated from a 64 bit counter
+func (n *UUIDNetwork) CounterFromUUID(u [16]byte) (uint64, error) {
+	hi, lo, err := unpackUUID(u)
+	if err != nil {
+		return 0, err
+	}
+
+	hi, lo, _ = n.encode(hi, lo, true)
+	if hi != 0 {
+		return 0, fmt.Errorf("%w, UUID %x doesn't map back to a 64 bit counter", ErrIndexGreatThanMaxValue, u)
+	}
+
+	return lo, nil
+}
+
+// MapUUID maps the random bits of a version 4 UUID and keeps the version and variant bits
+func (n *UUIDNetwork) MapUUID(u [16]byte) ([16]byte, error) {
+	return n.encodeUUID(u, false)
+}
+
+// InvertMapUUID performs an inversion of MapUUID
+func (n *UUIDNetwork) InvertMapUUID(u [16]byte) ([16]byte, error) {
+	return n.encodeUUID(u, true)
+}
+
+func (n *UUIDNetwork) encodeUUID(u [16]byte, invert bool) ([16]byte, error) {
+	hi, lo, err := unpackUUID(u)
+	if err != nil {
+		return u, err
+	}
+
+	hi, lo, _ = n.encode(hi, lo, invert)
+	return packUUID(hi, lo), nil
+}
+
+func (n *UUIDNetwork) encode(hi, lo uint64, invert bool) (uint64, uint64, error) {
+	if hi > uuidMaxHi {
+		return 0, 0, fmt.Errorf("%w, index: %#x%016x, maxSize: 2^122-1", ErrIndexGreatThanMaxValue, hi, lo)
+	}
+
+	a := lo & uuidHalfMask
+	b := hi<<(64-uuidHalfBits) | lo>>uuidHalfBits
+
+	start := 0
+	adjust := 1
+
+	if invert {
+		start = n.base.rounds - 1
+		adjust = -1
+	}
+
+	for round := start; round >= 0 && round < n.base.rounds; round += adjust {
+		seed := n.base.roundKey(round, 0)
+
+		if round%2 == 0 {
+			f := n.base.roundFunc.Round(seed, b, 1<<uuidHalfBits)
+			if invert {
+				a = (a - f) & uuidHalfMask
+			} else {
+				a = (a + f) & uuidHalfMask
+			}
+		} else {
+			f := n.base.roundFunc.Round(seed, a, 1<<uuidHalfBits)
+			if invert {
+				b = (b - f) & uuidHalfMask
+			} else {
+				b = (b + f) & uuidHalfMask
+			}
+		}
+	}
+
+	return b >> (64 - uuidHalfBits), b<<uuidHalfBits | a, nil
+}
+
+// packUUID writes a 122 bit value into a version 4 UUID, the top 48 bits go before the version,
+// the next 12 bits go between the version and the variant and the last 62 bits go after the variant
+func packUUID(hi, lo uint64) [16]byte {
+	// top is the 60 bits above the last 62
+	top := hi<<2 | lo>>62
+
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], top>>12<<16|0x4<<12|top&0xfff)
+	binary.BigEndian.PutUint64(u[8:], 0b10<<62|lo&(1<<62-1))
+
+	return u
+}
+
+// unpackUUID performs an inversion of packUUID
+func unpackUUID(u [16]byte) (uint64, uint64, error) {
+	first := binary.BigEndian.Uint64(u[:8])
+	second := binary.BigEndian.Uint64(u[8:])
+
+	if first>>12&0xf != 0x4 || second>>62 != 0b10 {
+		return 0, 0, fmt.Errorf("%w, UUID: %x", ErrNotUUIDv4, u)
+	}
+
+	top := first>>16<<12 | first&0xfff
+
+	return top >> 2, top<<62 | second&(1<<62-1), nil
+}
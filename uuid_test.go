@@ -0,0 +1,167 @@
+package feistel
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUUIDFromCounter(t *testing.T) {
+	net, err := NewUUIDNetwork(42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[[16]byte]struct{}, 10000)
+
+	for _, start := range []uint64{0, 1 << 40, math.MaxUint64 - 4999} {
+		for counter := start; counter-start < 5000; counter++ {
+			u := net.UUIDFromCounter(counter)
+
+			if u[6]>>4 != 4 {
+				t.Fatalf("UUID %x for %d doesn't have version 4", u, counter)
+			}
+
+			if u[8]>>6 != 0b10 {
+				t.Fatalf("UUID %x for %d doesn't have the RFC 9562 variant", u, counter)
+			}
+
+			if _, ok := seen[u]; ok {
+				t.Fatalf("UUID %x generated twice", u)
+			}
+			seen[u] = struct{}{}
+
+			decoded, err := net.CounterFromUUID(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded != counter {
+				t.Fatalf("UUID %x was generated from %d but decoded to %d", u, counter, decoded)
+			}
+		}
+	}
+}
+
+func TestUUIDBitsBalanced(t *testing.T) {
+	net, err := NewUUIDNetwork(42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples := 20000
+	var counts [128]int
+
+	for counter := range uint64(samples) {
+		u := net.UUIDFromCounter(counter)
+		for bit := range 128 {
+			if u[bit/8]>>(7-bit%8)&1 == 1 {
+				counts[bit]++
+			}
+		}
+	}
+
+	for bit, count := range counts {
+		if bit >= 48 && bit < 52 || bit == 64 || bit == 65 {
+			continue
+		}
+
+		// 5σ of a fair coin over the samples
+		if math.Abs(float64(count)-float64(samples)/2) > 5*math.Sqrt(float64(samples))/2 {
+			t.Errorf("Bit %d was set %d times out of %d", bit, count, samples)
+		}
+	}
+}
+
+func TestUUIDMapInvertible(t *testing.T) {
+	net, err := NewUUIDNetwork(7, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := [][2]uint64{{0, 0}, {0, 1}, {uuidMaxHi, math.MaxUint64}, {12345, 67890}, {1 << 57, 1 << 63}}
+
+	for _, value := range values {
+		hi, lo, err := net.Map(value[0], value[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hi > uuidMaxHi {
+			t.Fatalf("Mapped %v to %#x %#x which is outside of 122 bits", value, hi, lo)
+		}
+
+		invertedHi, invertedLo, err := net.InvertMap(hi, lo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if invertedHi != value[0] || invertedLo != value[1] {
+			t.Fatalf("Mapped %v to %#x %#x and inversion produced %#x %#x", value, hi, lo, invertedHi, invertedLo)
+		}
+
+		u := packUUID(value[0], value[1])
+		mapped, err := net.MapUUID(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if mapped != packUUID(hi, lo) {
+			t.Fatalf("MapUUID mapped %x to %x but Map produced %x", u, mapped, packUUID(hi, lo))
+		}
+
+		inverted, err := net.InvertMapUUID(mapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inverted != u {
+			t.Fatalf("MapUUID mapped %x to %x and inversion produced %x", u, mapped, inverted)
+		}
+	}
+}
+
+func TestPackUUID(t *testing.T) {
+	hi, lo, err := unpackUUID(packUUID(uuidMaxHi, math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hi != uuidMaxHi || lo != math.MaxUint64 {
+		t.Errorf("Expected all 122 bits to survive packing, got %#x %#x", hi, lo)
+	}
+
+	u := packUUID(0, 0)
+	if binary.BigEndian.Uint64(u[:8]) != 0x4000 || binary.BigEndian.Uint64(u[8:]) != 1<<63 {
+		t.Errorf("Expected only the version and variant bits to be set, got %x", u)
+	}
+}
+
+func TestUUIDErrors(t *testing.T) {
+	net, err := NewUUIDNetwork(42, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := net.Map(uuidMaxHi+1, 0); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue, got %v", err)
+	}
+
+	var v1 [16]byte
+	v1[6] = 0x10
+	v1[8] = 0x80
+	if _, err := net.CounterFromUUID(v1); !errors.Is(err, ErrNotUUIDv4) {
+		t.Errorf("Expected ErrNotUUIDv4 for a version 1 UUID, got %v", err)
+	}
+
+	// A valid UUID that wasn't generated from a counter is almost certain to decode above 64 bits
+	forged := packUUID(1, 2)
+	if _, err := net.CounterFromUUID(forged); !errors.Is(err, ErrIndexGreatThanMaxValue) {
+		t.Errorf("Expected ErrIndexGreatThanMaxValue for a forged UUID, got %v", err)
+	}
+
+	if _, err := NewUUIDNetwork(42, 0); !errors.Is(err, ErrRoundsMustBeSet) {
+		t.Errorf("Expected ErrRoundsMustBeSet, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package feistel
+
+import "errors"
+
+// ErrWalksMustBeSet is returned when WithConstantTime is given zero walks
+var ErrWalksMustBeSet = errors.New("feistel: constant time walks must be a non zero value")
+
+// WithMaxWalks is an option that caps how many times the rounds can run for a single Map or InvertMap.
+// When the domain size isn't exactly leftRadix * rightRadix a result can land outside of the domain and the rounds
+// are run again on it (cycle walking), usually only once or twice but with no upper bound. Once limit walks have run
+// without landing inside the domain a *WalkLimitError is returned instead. 0 means no limit, which is the default.
+// The rounds that did run are identical so every index that doesn't hit the limit maps the same as without the option.
+func WithMaxWalks(limit uint64) Option {
+	return func(m *Network) {
+		m.maxWalks = limit
+	}
+}
+
+// WithConstantTime is an option that makes every Map and InvertMap run the rounds exactly walks times,
+// after the result has landed inside the domain the remaining walks keep running on it and are discarded.
+// That way the time taken doesn't tell you how many walks an index needed. It implies WithMaxWalks(walks)
+// and returns the same *WalkLimitError when walks aren't enough. It evens out the amount of work, the round
+// function itself still has to be constant time for the whole call to be.
+func WithConstantTime(walks uint64) Option {
+	return func(m *Network) {
+		m.maxWalks = walks
+		m.constantTime = true
+	}
+}
+
+// withoutWalkLimit undoes WithMaxWalks and WithConstantTime, it's used to build the network the iterators run on
+func withoutWalkLimit() Option {
+	return func(m *Network) {
+		m.maxWalks = 0
+		m.constantTime = false
+	}
+}
+
+// withIndex replaces the index of a *WalkLimitError with the one the caller asked for,
+// cycleWalk only sees the position inside the epoch and WithEpochBoundarySpacing can swap that for another one
+func withIndex(err error, index uint64) error {
+	var walkErr *WalkLimitError
+	if errors.As(err, &walkErr) {
+		return &WalkLimitError{Index: index, Steps: walkErr.Steps}
+	}
+
+	return err
+}
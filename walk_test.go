@@ -0,0 +1,312 @@
+package feistel
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// walkMaxValues all need cycle walking, their domain sizes aren't a product of two close factors
+var walkMaxValues = []uint64{12, 100, 1020, 65_536}
+
+func TestMaxWalksMatchesUnlimited(t *testing.T) {
+	for _, maxValue := range walkMaxValues {
+		plain, err := NewNetwork(maxValue, 42, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		limited, err := NewNetwork(maxValue, 42, 8, WithMaxWalks(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failed := 0
+		for index := range maxValue + 1 {
+			expected, _ := plain.Map(index)
+
+			mapped, err := limited.Map(index)
+			if err != nil {
+				var walkErr *WalkLimitError
+				if !errors.As(err, &walkErr) || !errors.Is(err, ErrWalkLimitExceeded) || walkErr.Steps != 1 {
+					t.Fatalf("Expected a WalkLimitError after 1 step, got %v", err)
+				}
+				failed++
+				continue
+			}
+
+			if mapped != expected {
+				t.Fatalf("Expected %d to map to %d like without a limit, got %d", index, expected, mapped)
+			}
+
+			if inverted, err := limited.InvertMap(mapped); err == nil && inverted != index {
+				t.Fatalf("Mapped %d to %d and inversion produced %d", index, mapped, inverted)
+			}
+		}
+
+		if failed == 0 {
+			t.Errorf("Expected some indices of %d to need more than one walk", maxValue)
+		}
+	}
+}
+
+func TestConstantTime(t *testing.T) {
+	for _, maxValue := range walkMaxValues {
+		plain, err := NewNetwork(maxValue, 42, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		calls := 0
+		net, err := NewNetwork(maxValue, 42, 8, WithConstantTime(64), WithRoundFunc(countingRoundFunc{&calls}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for index := range min(maxValue+1, 2000) {
+			calls = 0
+			mapped, err := net.Map(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls != 64*8 {
+				t.Fatalf("Expected %d round calls for %d, got %d", 64*8, index, calls)
+			}
+
+			if expected, _ := plain.Map(index); mapped != expected {
+				t.Fatalf("Expected %d to map to %d like without constant time, got %d", index, expected, mapped)
+			}
+
+			calls = 0
+			if inverted, err := net.InvertMap(mapped); err != nil || inverted != index || calls != 64*8 {
+				t.Fatalf("Mapped %d to %d and inversion produced %d with %d round calls, %v", index, mapped, inverted, calls, err)
+			}
+		}
+	}
+}
+
+func TestWalkLimitErrorIndex(t *testing.T) {
+	const maxValue = 100
+
+	for _, opts := range [][]Option{{WithEpochs()}, {WithEpochs(), WithEpochBoundarySpacing(30)}} {
+		net, err := NewNetwork(maxValue, 42, 8, append(opts, WithMaxWalks(1))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failed := 0
+		for index := uint64(maxValue + 1); index < 20*(maxValue+1); index++ {
+			_, err := net.Map(index)
+			if err == nil {
+				continue
+			}
+			failed++
+
+			var walkErr *WalkLimitError
+			if !errors.As(err, &walkErr) || walkErr.Index != index {
+				t.Fatalf("Expected a WalkLimitError for index %d, got %v", index, err)
+			}
+
+			if err := net.MapRange(make([]uint64, 1), index); !errors.As(err, &walkErr) || walkErr.Index != index {
+				t.Fatalf("Expected MapRange to report index %d, got %v", index, err)
+			}
+
+			epoch, offset := net.EpochOf(index)
+			if _, err := net.MapEpoch(epoch, offset); !errors.As(err, &walkErr) || walkErr.Index != offset {
+				t.Fatalf("Expected MapEpoch to report offset %d, got %v", offset, err)
+			}
+		}
+
+		if failed == 0 {
+			t.Errorf("Expected some indices to hit the walk limit")
+		}
+
+		for value := uint64(maxValue + 1); value < 20*(maxValue+1); value++ {
+			var walkErr *WalkLimitError
+			if _, err := net.InvertMap(value); err != nil && (!errors.As(err, &walkErr) || walkErr.Index != value) {
+				t.Fatalf("Expected InvertMap to report value %d, got %v", value, err)
+			}
+		}
+	}
+
+	bigNet, err := NewBigNetwork(big.NewInt(maxValue), 42, 8, WithEpochs(), WithMaxWalks(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Past 2^64 the error can't be a *WalkLimitError but it still has to name the index
+	failed := 0
+	for i := range int64(20 * (maxValue + 1)) {
+		index := new(big.Int).Lsh(big.NewInt(1), 70)
+		index.Add(index, big.NewInt(i))
+
+		if _, err := bigNet.Map(index); err != nil {
+			failed++
+			if !errors.Is(err, ErrWalkLimitExceeded) || !strings.Contains(err.Error(), "index: "+index.String()) {
+				t.Fatalf("Expected a walk limit error naming %v, got %v", index, err)
+			}
+		}
+	}
+
+	if failed == 0 {
+		t.Errorf("Expected some BigNetwork indices to hit the walk limit")
+	}
+}
+
+func TestMaxWalksWithBoundarySpacing(t *testing.T) {
+	plain, err := NewNetwork(100, 42, 8, WithEpochs(), WithEpochBoundarySpacing(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited, err := NewNetwork(100, 42, 8, WithEpochs(), WithEpochBoundarySpacing(30), WithMaxWalks(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unspaced, err := NewNetwork(100, 42, 8, WithEpochs(), WithMaxWalks(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed, unspacedFailed := 0, 0
+	for index := range uint64(2020) {
+		if _, err := unspaced.Map(index); err != nil {
+			unspacedFailed++
+		}
+
+		mapped, err := limited.Map(index)
+		if err != nil {
+			failed++
+			continue
+		}
+
+		if expected, _ := plain.Map(index); mapped != expected {
+			t.Fatalf("Expected %d to map to %d like without a limit, got %d", index, expected, mapped)
+		}
+	}
+
+	// Building the swaps mustn't count against the limit, the swaps only move which position has to walk
+	if failed > 2*unspacedFailed {
+		t.Errorf("Expected about as many failures as without spacing (%d), got %d", unspacedFailed, failed)
+	}
+}
+
+func TestConstantTimeBatch(t *testing.T) {
+	net, err := NewNetwork(1020, 42, 8, WithConstantTime(1), WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := make([]uint64, 1021)
+	if err := net.MapRange(dst, 0); !errors.Is(err, ErrWalkLimitExceeded) {
+		t.Errorf("Expected ErrWalkLimitExceeded from MapRange, got %v", err)
+	}
+
+	plain, err := NewNetwork(1020, 42, 8, WithEpochs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := uint64(0)
+	for index, value := range net.Range(0, 2041) {
+		if expected, _ := plain.Map(index); value != expected {
+			t.Fatalf("Expected Range to map %d to %d like without the walk limit, got %d", index, expected, value)
+		}
+
+		if limited, err := net.Map(index); err == nil && limited != value {
+			t.Fatalf("Range mapped %d to %d but Map returned %d", index, value, limited)
+		}
+		count++
+	}
+
+	if count != 2042 {
+		t.Errorf("Expected Range to visit 2042 indices, got %d", count)
+	}
+
+	for value, index := range net.InvertAll() {
+		if expected, _ := plain.InvertMap(value); index != expected {
+			t.Fatalf("Expected InvertAll to invert %d to %d like without the walk limit, got %d", value, expected, index)
+		}
+	}
+}
+
+func TestKeyedWalkLimitIterators(t *testing.T) {
+	limited, err := NewKeyedNetwork(1020, testKey128, 8, WithMaxWalks(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := NewKeyedNetwork(1020, testKey128, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, value := range limited.All() {
+		if expected, _ := plain.Map(index); value != expected {
+			t.Fatalf("Expected All to map %d to %d like the keyed network without a limit, got %d", index, expected, value)
+		}
+	}
+}
+
+func TestConstantTimeNeedsWalks(t *testing.T) {
+	if _, err := NewNetwork(100, 42, 8, WithConstantTime(0)); !errors.Is(err, ErrWalksMustBeSet) {
+		t.Errorf("Expected ErrWalksMustBeSet, got %v", err)
+	}
+
+	// A limit of 0 is no limit
+	if _, err := NewNetwork(100, 42, 8, WithMaxWalks(0)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// BenchmarkWalks maps every index of each domain and reports how many walks they needed,
+// use it to pick a limit for WithMaxWalks or WithConstantTime
+func BenchmarkWalks(b *testing.B) {
+	for _, maxValue := range append(slices.Clone(maxValuesToTest), walkMaxValues...) {
+		b.Run(fmt.Sprintf("maxValue %d", maxValue), func(b *testing.B) {
+			calls := 0
+			net, err := NewNetwork(maxValue, 42, 8, WithRoundFunc(countingRoundFunc{&calls}))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			domainSize := min(maxValue+1, 1<<16)
+			histogram := make(map[int]int)
+
+			for i := range b.N {
+				calls = 0
+				if _, err := net.Map(uint64(i) % domainSize); err != nil {
+					b.Fatal(err)
+				}
+				histogram[calls/8]++
+			}
+
+			walks := slices.Sorted(func(yield func(int) bool) {
+				for w := range histogram {
+					if !yield(w) {
+						return
+					}
+				}
+			})
+
+			total, seen, p99 := 0, 0, 0
+			for _, w := range walks {
+				total += w * histogram[w]
+				seen += histogram[w]
+				if p99 == 0 && seen*100 >= b.N*99 {
+					p99 = w
+				}
+			}
+
+			b.ReportMetric(float64(total)/float64(b.N), "walks/op")
+			b.ReportMetric(float64(p99), "p99-walks")
+			b.ReportMetric(float64(walks[len(walks)-1]), "max-walks")
+			b.ReportMetric(float64(histogram[1])/float64(b.N), "single-walk-ratio")
+		})
+	}
+}